@@ -0,0 +1,178 @@
+package contabo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const cloudConfigHeader = "#cloud-config"
+const cloudConfigContentType = "text/cloud-config"
+
+// maxUserDataSize is the maximum size of the user data in bytes. User data
+// is sent to the API as it is, so gzipped payloads count with their
+// compressed and base64 encoded size.
+const maxUserDataSize = 65536
+
+func validateUserData(v interface{}, k string) (warnings []string, errors []error) {
+	userData := v.(string)
+	if len(userData) > maxUserDataSize {
+		return warnings, append(errors, fmt.Errorf("%q must not be larger than %d bytes, got %d bytes, gzip and base64 encode it to make it smaller", k, maxUserDataSize, len(userData)))
+	}
+	decodedUserData, err := decodeUserData(userData)
+	if err != nil {
+		return warnings, append(errors, fmt.Errorf("%q could not be decoded: %v", k, err))
+	}
+	if err := validateCloudInit(decodedUserData); err != nil {
+		errors = append(errors, fmt.Errorf("%q is not valid cloud-init user data: %v", k, err))
+	}
+	return warnings, errors
+}
+
+// decodeUserData unpacks gzipped and base64 encoded user data, so its
+// content can be validated. Everything else is returned as is. The API
+// receives the user data unchanged, cloud-init unpacks it while booting.
+func decodeUserData(userData string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(userData))
+	if err != nil || len(decoded) < 2 || decoded[0] != 0x1f || decoded[1] != 0x8b {
+		return userData, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	unpacked, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(unpacked), nil
+}
+
+func validateCloudInit(userData string) error {
+	switch {
+	case strings.HasPrefix(userData, cloudConfigHeader):
+		return validateCloudConfig(userData)
+	case strings.HasPrefix(strings.ToLower(userData), "content-type: multipart/"):
+		return validateMultipartCloudInit(userData)
+	}
+	return nil
+}
+
+func validateCloudConfig(cloudConfig string) error {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal([]byte(cloudConfig), &config); err != nil {
+		return fmt.Errorf("invalid cloud-config YAML: %v", err)
+	}
+	return nil
+}
+
+func validateMultipartCloudInit(userData string) error {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(userData)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return fmt.Errorf("invalid MIME header: %v", err)
+	}
+
+	_, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("invalid MIME content type: %v", err)
+	}
+
+	parts := multipart.NewReader(reader.R, params["boundary"])
+	for index := 0; ; index++ {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid MIME part %d: %v", index, err)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if mediaType != cloudConfigContentType {
+			continue
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("invalid MIME part %d: %v", index, err)
+		}
+		if err := validateCloudConfig(string(content)); err != nil {
+			return fmt.Errorf("MIME part %d: %v", index, err)
+		}
+	}
+}
+
+type cloudInitPart struct {
+	contentType string
+	content     string
+	filename    string
+	mergeType   string
+}
+
+func renderCloudInitConfig(parts []cloudInitPart, boundary string) (string, error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&buffer, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary)
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n\r\n")
+
+	for index, part := range parts {
+		if part.contentType == cloudConfigContentType {
+			if err := validateCloudConfig(part.content); err != nil {
+				return "", fmt.Errorf("part %d: %v", index, err)
+			}
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "7bit")
+		header.Set("Mime-Version", "1.0")
+		if part.filename != "" {
+			header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", part.filename))
+		}
+		if part.mergeType != "" {
+			header.Set("X-Merge-Type", part.mergeType)
+		}
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err := partWriter.Write([]byte(part.content)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func encodeUserData(userData string) (string, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(userData)); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
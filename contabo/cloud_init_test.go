@@ -0,0 +1,126 @@
+package contabo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestDecodeUserData(t *testing.T) {
+	gzipped, err := encodeUserData("#cloud-config\npackages:\n  - nginx\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var truncated bytes.Buffer
+	writer := gzip.NewWriter(&truncated)
+	writer.Write([]byte("#cloud-config\n"))
+	writer.Close()
+
+	cases := []struct {
+		name     string
+		userData string
+		decoded  string
+		fails    bool
+	}{
+		{name: "plain", userData: "#cloud-config\npackages: []\n", decoded: "#cloud-config\npackages: []\n"},
+		{name: "shell script", userData: "#!/bin/sh\necho done\n", decoded: "#!/bin/sh\necho done\n"},
+		{name: "gzipped", userData: gzipped, decoded: "#cloud-config\npackages:\n  - nginx\n"},
+		{name: "gzipped with newline", userData: gzipped + "\n", decoded: "#cloud-config\npackages:\n  - nginx\n"},
+		{name: "base64 without gzip", userData: base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")), decoded: base64.StdEncoding.EncodeToString([]byte("#cloud-config\n"))},
+		{name: "corrupt gzip", userData: base64.StdEncoding.EncodeToString(truncated.Bytes()[:12]), fails: true},
+	}
+	for _, c := range cases {
+		decoded, err := decodeUserData(c.userData)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%s: decodeUserData() succeeded, want an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: decodeUserData() failed: %v", c.name, err)
+		} else if decoded != c.decoded {
+			t.Errorf("%s: decodeUserData() = %q, want %q", c.name, decoded, c.decoded)
+		}
+	}
+}
+
+func TestValidateUserData(t *testing.T) {
+	invalidGzipped, err := encodeUserData("#cloud-config\npackages: [\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validGzipped, err := encodeUserData("#cloud-config\nruncmd:\n  - " + strings.Repeat("x", 2*maxUserDataSize) + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		userData string
+		valid    bool
+	}{
+		{name: "cloud-config", userData: "#cloud-config\npackages:\n  - nginx\n", valid: true},
+		{name: "empty cloud-config", userData: "#cloud-config\n", valid: true},
+		{name: "invalid yaml", userData: "#cloud-config\npackages: [\n", valid: false},
+		{name: "cloud-config is no map", userData: "#cloud-config\n- nginx\n", valid: false},
+		{name: "shell script", userData: "#!/bin/sh\necho {\n", valid: true},
+		{name: "gzipped invalid yaml", userData: invalidGzipped, valid: false},
+		{name: "too large", userData: "#!/bin/sh\n" + strings.Repeat("x", maxUserDataSize), valid: false},
+		{name: "large but gzipped", userData: validGzipped, valid: true},
+		{name: "multipart", userData: "Content-Type: multipart/mixed; boundary=\"B\"\r\nMIME-Version: 1.0\r\n\r\n--B\r\nContent-Type: text/cloud-config\r\n\r\npackages: []\r\n--B--\r\n", valid: true},
+		{name: "multipart with invalid part", userData: "Content-Type: multipart/mixed; boundary=\"B\"\r\nMIME-Version: 1.0\r\n\r\n--B\r\nContent-Type: text/cloud-config\r\n\r\npackages: [\r\n--B--\r\n", valid: false},
+		{name: "multipart skips scripts", userData: "Content-Type: multipart/mixed; boundary=\"B\"\r\nMIME-Version: 1.0\r\n\r\n--B\r\nContent-Type: text/x-shellscript\r\n\r\necho [\r\n--B--\r\n", valid: true},
+		{name: "multipart without boundary", userData: "Content-Type: multipart/mixed; boundary=\"B\"\r\nMIME-Version: 1.0\r\n\r\npackages: []\r\n", valid: false},
+	}
+	for _, c := range cases {
+		_, errors := validateUserData(c.userData, "user_data")
+		if c.valid != (len(errors) == 0) {
+			t.Errorf("%s: valid = %t, errors %v", c.name, c.valid, errors)
+		}
+	}
+}
+
+func TestRenderCloudInitConfig(t *testing.T) {
+	parts := []cloudInitPart{
+		{contentType: cloudConfigContentType, content: "packages:\n  - nginx\n", mergeType: "list(append)+dict(recurse_array)+str()"},
+		{contentType: "text/x-shellscript", content: "#!/bin/sh\necho done\n", filename: "setup.sh"},
+	}
+	rendered, err := renderCloudInitConfig(parts, "MIMEBOUNDARY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\r\n",
+		"--MIMEBOUNDARY\r\n",
+		"Content-Type: text/cloud-config\r\n",
+		"X-Merge-Type: list(append)+dict(recurse_array)+str()\r\n",
+		"Content-Disposition: attachment; filename=\"setup.sh\"\r\n",
+		"--MIMEBOUNDARY--",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("rendered config does not contain %q:\n%s", expected, rendered)
+		}
+	}
+	if strings.Index(rendered, "packages:") > strings.Index(rendered, "echo done") {
+		t.Errorf("parts are not rendered in order:\n%s", rendered)
+	}
+	if err := validateCloudInit(rendered); err != nil {
+		t.Errorf("rendered config is invalid: %v", err)
+	}
+
+	encoded, err := encodeUserData(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := decodeUserData(encoded); err != nil || decoded != rendered {
+		t.Errorf("decodeUserData(encodeUserData()) = %q, %v, want the rendered config", decoded, err)
+	}
+
+	if _, err := renderCloudInitConfig([]cloudInitPart{{contentType: cloudConfigContentType, content: "packages: [\n"}}, "MIMEBOUNDARY"); err == nil {
+		t.Error("rendering an invalid cloud-config part succeeded, want an error")
+	}
+}
@@ -0,0 +1,113 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCloudInitConfig() *schema.Resource {
+	return &schema.Resource{
+		Description: "Renders a multipart [cloud-init](https://cloudinit.readthedocs.io/en/latest/topics/format.html#mime-multi-part-archive) config from several parts which can be passed to the `user_data` of a compute instance. Parts with content type `text/cloud-config` are validated while planning.",
+		ReadContext: dataSourceCloudInitConfigRead,
+		Schema: map[string]*schema.Schema{
+			"part": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "A part of the multipart cloud-init config. Parts are rendered in the given order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"content_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     cloudConfigContentType,
+							Description: "The MIME content type of the part, e.g. `text/cloud-config` or `text/x-shellscript`. Default is `text/cloud-config`.",
+						},
+						"content": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The content of the part.",
+						},
+						"filename": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The filename of the part which is passed to cloud-init.",
+						},
+						"merge_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "How cloud-init merges this part with the previous ones, e.g. `list(append)+dict(recurse_array)+str()`.",
+						},
+					},
+				},
+			},
+			"gzip": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Compress the rendered config with gzip and encode it with base64. The `user_data` of a compute instance accepts such payloads, they are sent compressed and count with their compressed size against the size limit of the API.",
+			},
+			"boundary": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "MIMEBOUNDARY",
+				Description: "The boundary which separates the parts of the rendered config.",
+			},
+			"rendered": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The rendered cloud-init config.",
+			},
+		},
+	}
+}
+
+func dataSourceCloudInitConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var parts []cloudInitPart
+	for _, part := range d.Get("part").([]interface{}) {
+		partMap := part.(map[string]interface{})
+		parts = append(parts, cloudInitPart{
+			contentType: partMap["content_type"].(string),
+			content:     partMap["content"].(string),
+			filename:    partMap["filename"].(string),
+			mergeType:   partMap["merge_type"].(string),
+		})
+	}
+
+	rendered, err := renderCloudInitConfig(parts, d.Get("boundary").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if d.Get("gzip").(bool) {
+		rendered, err = encodeUserData(rendered)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// the rendered config is sent to the API as it is
+	if len(rendered) > maxUserDataSize {
+		detail := fmt.Sprintf("The rendered cloud-init config has %d bytes, the API accepts at most %d bytes of user data.", len(rendered), maxUserDataSize)
+		if !d.Get("gzip").(bool) {
+			detail += " Set gzip to compress it."
+		}
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Rendered cloud-init config is too large",
+			Detail:   detail,
+		})
+	}
+
+	if err := d.Set("rendered", rendered); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(rendered)))
+
+	return diags
+}
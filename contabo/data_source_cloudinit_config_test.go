@@ -0,0 +1,55 @@
+package contabo
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccContaboCloudInitConfigBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboCloudInitConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.contabo_cloudinit_config.new", "rendered", regexp.MustCompile("Content-Type: text/cloud-config")),
+					resource.TestMatchResourceAttr("data.contabo_cloudinit_config.new", "rendered", regexp.MustCompile("filename=\"setup.sh\"")),
+				),
+			},
+			{
+				Config:      testCheckContaboCloudInitConfigInvalid(),
+				ExpectError: regexp.MustCompile("invalid cloud-config YAML"),
+			},
+		},
+	})
+}
+
+func testCheckContaboCloudInitConfigBasic() string {
+	return `
+		provider "contabo" {}
+
+		data "contabo_cloudinit_config" "new" {
+			part {
+				content = "#cloud-config\npackages:\n  - nginx\n"
+			}
+			part {
+				content_type = "text/x-shellscript"
+				filename     = "setup.sh"
+				content      = "#!/bin/sh\necho done\n"
+			}
+		}
+	`
+}
+
+func testCheckContaboCloudInitConfigInvalid() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "invalid_user_data" {
+			user_data = "#cloud-config\npackages: [\n"
+		}
+	`
+}
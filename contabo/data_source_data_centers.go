@@ -0,0 +1,147 @@
+package contabo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+func dataSourceDataCenters() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the data centers in which compute instances, object storages and private networks can be located.",
+		ReadContext: dataSourceDataCentersRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the filters.",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return data centers in this region, e.g. `EU`.",
+			},
+			"capability": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return data centers offering this capability, e.g. `VPS` or `Object-Storage`.",
+			},
+			"data_centers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching data centers.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the data center.",
+						},
+						"slug": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Slug of the data center.",
+						},
+						"region": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Slug of the region of the data center, use it as `region` of a compute instance.",
+						},
+						"region_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the region of the data center.",
+						},
+						"capabilities": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The products offered in the data center.",
+						},
+						"s3_url": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The S3 URL of object storages in the data center.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDataCentersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*apiClient.APIClient)
+
+	region := d.Get("region").(string)
+	capability := d.Get("capability").(string)
+
+	dataCenters, diags := retrieveAllDataCenters(ctx, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	flattenedDataCenters := []interface{}{}
+	for _, dataCenter := range dataCenters {
+		if region != "" && !strings.EqualFold(dataCenter.RegionSlug, region) {
+			continue
+		}
+		if capability != "" && !containsFold(dataCenter.Capabilities, capability) {
+			continue
+		}
+		flattenedDataCenters = append(flattenedDataCenters, map[string]interface{}{
+			"name":         dataCenter.Name,
+			"slug":         dataCenter.Slug,
+			"region":       dataCenter.RegionSlug,
+			"region_name":  dataCenter.RegionName,
+			"capabilities": dataCenter.Capabilities,
+			"s3_url":       dataCenter.S3Url,
+		})
+	}
+
+	if err := d.Set("data_centers", flattenedDataCenters); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(strings.ToLower(region + "|" + capability))))
+
+	return diags
+}
+
+// retrieveAllDataCenters pages through the data centers.
+func retrieveAllDataCenters(ctx context.Context, client *apiClient.APIClient) ([]apiClient.DataCenterResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var dataCenters []apiClient.DataCenterResponse
+
+	for page := int64(1); ; page++ {
+		res, httpResp, err := client.DataCentersApi.
+			RetrieveDataCenterList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize).
+			Execute()
+
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		dataCenters = append(dataCenters, res.Data...)
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return dataCenters, diags
+		}
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package contabo
 
 import (
 	"context"
+	"fmt"
 
 	"contabo.com/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -11,6 +12,7 @@ import (
 
 func dataSourceFirewall() *schema.Resource {
 	return &schema.Resource{
+		Description: "Looks up a firewall by its identifier or by its name.",
 		ReadContext: dataSourceFirewallRead,
 		Schema: map[string]*schema.Schema{
 			"created_date": &schema.Schema{
@@ -20,14 +22,17 @@ func dataSourceFirewall() *schema.Resource {
 				Description: "The creation date of the Firewall.",
 			},
 			"id": &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The identifier of the Firewall. Use it to manage it!",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The identifier of the Firewall. Use it to manage it!",
 			},
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the Firewall. It may contain letters, numbers, colons, dashes, and underscores. There is a limit of 255 characters per Firewall name.",
+				Optional:    true,
+				Computed:    true,
+				Description: "The name of the Firewall. Can be used instead of `id` to look up the Firewall, it has to be unique.",
 			},
 			"description": &schema.Schema{
 				Type:        schema.TypeString,
@@ -36,8 +41,9 @@ func dataSourceFirewall() *schema.Resource {
 			},
 			"status": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Status of the Firewall. It can be `active`, or `inactive`.",
+				Optional:    true,
+				Computed:    true,
+				Description: "Status of the Firewall. It can be `active`, or `inactive`. When set, a lookup by `name` only considers firewalls with this status.",
 			},
 			"instance_ids": {
 				Type:        schema.TypeSet,
@@ -48,7 +54,6 @@ func dataSourceFirewall() *schema.Resource {
 			"instances_status": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Optional:    true,
 				Description: "The status of every instance in the firewall",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -65,8 +70,7 @@ func dataSourceFirewall() *schema.Resource {
 						"error_message": {
 							Type:        schema.TypeString,
 							Computed:    true,
-							Optional:    true,
-							Description: "Status of the instance.",
+							Description: "The reason why the instance could not be assigned.",
 						},
 					},
 				},
@@ -77,57 +81,21 @@ func dataSourceFirewall() *schema.Resource {
 				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"inbound": {
-							Type:        schema.TypeList,
+						inboundRuleKey: {
+							Type:        schema.TypeSet,
+							Set:         hashFirewallRule,
 							Computed:    true,
 							Optional:    true,
 							Description: "Inbound rules for this Firewall",
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"protocol": {
-										Type:        schema.TypeString,
-										Computed:    true,
-										Optional:    true,
-										Description: "Define the protocol for the rule. Allowed protocols are `tcp`, `udp` and `icmp`.",
-									},
-									"action": {
-										Type:        schema.TypeString,
-										Computed:    true,
-										Optional:    true,
-										Description: "Action of the rule, currently there is just `accept`.",
-									},
-									"status": {
-										Type:        schema.TypeString,
-										Optional:    true,
-										Description: "Status of the rule. It can be `active`, or `inactive`.",
-									},
-									"dest_ports": {
-										Type:     schema.TypeSet,
-										Optional: true,
-										Elem:     &schema.Schema{Type: schema.TypeString},
-									},
-									"src_cidr": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												"ipv4": &schema.Schema{
-													Type:        schema.TypeSet,
-													Elem:        &schema.Schema{Type: schema.TypeString},
-													Optional:    true,
-													Description: "Provide allowed IPv4 addresses as string array for this rule",
-												},
-												"ipv6": &schema.Schema{
-													Type:        schema.TypeSet,
-													Elem:        &schema.Schema{Type: schema.TypeString},
-													Optional:    true,
-													Description: "Provide allowed IPv6 addresses as string array for this rule",
-												},
-											},
-										},
-									},
-								},
-							},
+							Elem:        firewallRuleElem(),
+						},
+						outboundRuleKey: {
+							Type:        schema.TypeSet,
+							Set:         hashFirewallRule,
+							Computed:    true,
+							Optional:    true,
+							Description: "Outbound rules for this Firewall. They have the same shape as the inbound rules and restrict the traffic leaving the instances.",
+							Elem:        firewallRuleElem(),
 						},
 					},
 				},
@@ -141,6 +109,12 @@ func dataSourceFirewallRead(ctx context.Context, d *schema.ResourceData, m inter
 	client := m.(*openapi.APIClient)
 
 	firewallId := d.Get("id").(string)
+	if firewallId == "" {
+		firewallId, diags = findFirewallByName(ctx, client, d.Get("name").(string), d.Get("status").(string))
+		if diags.HasError() {
+			return diags
+		}
+	}
 
 	res, httpResp, err := client.FirewallsApi.
 		RetrieveFirewall(ctx, firewallId).
@@ -158,3 +132,29 @@ func dataSourceFirewallRead(ctx context.Context, d *schema.ResourceData, m inter
 	resultDiags, _ := AddFirewallToData(res.Data[0], d, diags)
 	return resultDiags
 }
+
+// findFirewallByName returns the id of the only firewall with the given name
+// and, if set, status.
+func findFirewallByName(ctx context.Context, client *openapi.APIClient, name string, status string) (string, diag.Diagnostics) {
+	firewalls, diags := retrieveAllFirewalls(ctx, client, func(request openapi.ApiRetrieveFirewallListRequest) openapi.ApiRetrieveFirewallListRequest {
+		return request.Name(name)
+	})
+	if diags.HasError() {
+		return "", diags
+	}
+
+	// the list endpoint also returns partial matches
+	var matches []string
+	for _, firewall := range firewalls {
+		if firewall.Name == name && (status == "" || firewall.Status == status) {
+			matches = append(matches, firewall.FirewallId)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", HandleMissingDataObjectsFilters(diags, "No firewall found", fmt.Sprintf("There is no firewall with the name %q.", name))
+	} else if len(matches) > 1 {
+		return "", HandleMissingDataObjectsFilters(diags, "Multiple firewalls found", fmt.Sprintf("There are %d firewalls with the name %q, please use id instead.", len(matches), name))
+	}
+	return matches[0], diags
+}
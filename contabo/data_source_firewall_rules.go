@@ -0,0 +1,298 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// firewallServicePreset is the traffic of a named service.
+type firewallServicePreset struct {
+	protocol  string
+	destPorts []string
+}
+
+var firewallServicePresets = map[string]firewallServicePreset{
+	"ssh":       {protocol: "tcp", destPorts: []string{"22"}},
+	"http":      {protocol: "tcp", destPorts: []string{"80"}},
+	"https":     {protocol: "tcp", destPorts: []string{"443"}},
+	"postgres":  {protocol: "tcp", destPorts: []string{"5432"}},
+	"wireguard": {protocol: "udp", destPorts: []string{"51820"}},
+	"icmp":      {protocol: "icmp"},
+}
+
+// firewallCidrGroup is a named set of source networks.
+type firewallCidrGroup struct {
+	ipv4 []string
+	ipv6 []string
+}
+
+var firewallCidrGroups = map[string]firewallCidrGroup{
+	"any": {
+		ipv4: []string{"0.0.0.0/0"},
+		ipv6: []string{"::/0"},
+	},
+	"private": {
+		ipv4: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		ipv6: []string{"fc00::/7"},
+	},
+}
+
+func dataSourceFirewallRules() *schema.Resource {
+	return &schema.Resource{
+		Description: "Expands named services like `ssh` or `https` and groups of source networks into firewall rules. Use the `rules` as `inbound` or `outbound` rules of a `contabo_firewall`.",
+		ReadContext: dataSourceFirewallRulesRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the expanded rules.",
+			},
+			"cidr_group": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "A named group of source networks which can be referenced by `cidr_groups` of a rule. The groups `any` and `private` are built in.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the group.",
+						},
+						"ipv4": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The IPv4 addresses and networks of the group.",
+						},
+						"ipv6": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The IPv6 addresses and networks of the group.",
+						},
+					},
+				},
+			},
+			"rule": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "A rule for a named service. Each rule needs at least one source from `cidr_groups`, `ipv4` or `ipv6`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(firewallServicePresetNames(), false),
+							Description:  "The name of the service. It can be `" + strings.Join(firewallServicePresetNames(), "`, `") + "`.",
+						},
+						"cidr_groups": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The names of the source network groups, either built in (`any` for every address, `private` for the private networks of RFC 1918 and RFC 4193) or defined with `cidr_group`.",
+						},
+						"ipv4": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Additional IPv4 addresses and networks which are allowed.",
+						},
+						"ipv6": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Additional IPv6 addresses and networks which are allowed.",
+						},
+						"status": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "active",
+							ValidateFunc: validation.StringInSlice([]string{"active", "inactive"}, false),
+							Description:  "Status of the rule. It can be `active`, or `inactive`.",
+						},
+					},
+				},
+			},
+			"rules": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The expanded rules in the order of `rule`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"protocol": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The protocol of the rule.",
+						},
+						"dest_ports": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The destination ports of the rule.",
+						},
+						"src_cidr": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ipv4": {
+										Type:        schema.TypeList,
+										Computed:    true,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Description: "The allowed IPv4 addresses and networks.",
+									},
+									"ipv6": {
+										Type:        schema.TypeList,
+										Computed:    true,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Description: "The allowed IPv6 addresses and networks.",
+									},
+								},
+							},
+						},
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Action of the rule, always `accept`.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Status of the rule.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFirewallRulesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	rules, err := expandFirewallRulePresets(d.Get("rule").([]interface{}), d.Get("cidr_group").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ruleRequests := buildFirewallRuleRequests(rules)
+	keys := make([]string, 0, len(ruleRequests))
+	for _, ruleRequest := range ruleRequests {
+		keys = append(keys, firewallRuleRequestKey(ruleRequest)+"|"+ruleRequest.Status)
+	}
+
+	if err := d.Set("rules", buildFirewallRuleRequestList(ruleRequests)); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(keys, ";"))))
+
+	return diags
+}
+
+// expandFirewallRulePresets expands the rule blocks of contabo_firewall_rules
+// to rules in the shape of the inbound and outbound rules of
+// contabo_firewall.
+func expandFirewallRulePresets(ruleBlocks []interface{}, cidrGroupBlocks []interface{}) ([]interface{}, error) {
+	cidrGroups := make(map[string]firewallCidrGroup, len(firewallCidrGroups)+len(cidrGroupBlocks))
+	for name, cidrGroup := range firewallCidrGroups {
+		cidrGroups[name] = cidrGroup
+	}
+	for _, cidrGroupBlock := range cidrGroupBlocks {
+		cidrGroupMap := cidrGroupBlock.(map[string]interface{})
+		name := cidrGroupMap["name"].(string)
+		if _, ok := firewallCidrGroups[name]; ok {
+			return nil, fmt.Errorf("cidr_group %q is built in and cannot be redefined", name)
+		} else if _, ok := cidrGroups[name]; ok {
+			return nil, fmt.Errorf("cidr_group %q is defined more than once", name)
+		}
+		cidrGroups[name] = firewallCidrGroup{
+			ipv4: stringsOf(cidrGroupMap["ipv4"]),
+			ipv6: stringsOf(cidrGroupMap["ipv6"]),
+		}
+	}
+
+	rules := make([]interface{}, 0, len(ruleBlocks))
+	for i, ruleBlock := range ruleBlocks {
+		ruleMap := ruleBlock.(map[string]interface{})
+		service := ruleMap["service"].(string)
+		preset, ok := firewallServicePresets[service]
+		if !ok {
+			return nil, fmt.Errorf("rule %d: unknown service %q", i, service)
+		}
+
+		ipv4 := stringsOf(ruleMap["ipv4"])
+		ipv6 := stringsOf(ruleMap["ipv6"])
+		for _, name := range stringsOf(ruleMap["cidr_groups"]) {
+			cidrGroup, ok := cidrGroups[name]
+			if !ok {
+				return nil, fmt.Errorf("rule %d: unknown cidr group %q", i, name)
+			}
+			ipv4 = append(ipv4, cidrGroup.ipv4...)
+			ipv6 = append(ipv6, cidrGroup.ipv6...)
+		}
+		if len(ipv4) == 0 && len(ipv6) == 0 {
+			return nil, fmt.Errorf("rule %d: the %s rule has no source, set cidr_groups, ipv4 or ipv6", i, service)
+		}
+
+		rules = append(rules, map[string]interface{}{
+			"protocol":   preset.protocol,
+			"dest_ports": schema.NewSet(hashPortRange, interfacesOf(preset.destPorts)),
+			"src_cidr": []interface{}{
+				map[string]interface{}{
+					"ipv4": schema.NewSet(hashCidr, interfacesOf(ipv4)),
+					"ipv6": schema.NewSet(hashCidr, interfacesOf(ipv6)),
+				},
+			},
+			"action": "accept",
+			"status": ruleMap["status"].(string),
+		})
+	}
+	return rules, nil
+}
+
+// buildFirewallRuleRequestList converts rule requests to the shape of the
+// rules attribute of contabo_firewall_rules.
+func buildFirewallRuleRequestList(ruleRequests []openapi.FirewallRuleRequest) []interface{} {
+	ruleList := make([]interface{}, 0, len(ruleRequests))
+	for _, ruleRequest := range ruleRequests {
+		ruleList = append(ruleList, map[string]interface{}{
+			"protocol":   ruleRequest.Protocol,
+			"dest_ports": normalizeAll(ruleRequest.DestPorts, normalizePortRange),
+			"src_cidr": []interface{}{
+				map[string]interface{}{
+					"ipv4": normalizeAll(ruleRequest.SrcCidr.GetIpv4(), normalizeCidr),
+					"ipv6": normalizeAll(ruleRequest.SrcCidr.GetIpv6(), normalizeCidr),
+				},
+			},
+			"action": ruleRequest.Action,
+			"status": ruleRequest.Status,
+		})
+	}
+	return ruleList
+}
+
+func firewallServicePresetNames() []string {
+	names := make([]string, 0, len(firewallServicePresets))
+	for name := range firewallServicePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func interfacesOf(values []string) []interface{} {
+	interfaces := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		interfaces = append(interfaces, value)
+	}
+	return interfaces
+}
@@ -0,0 +1,143 @@
+package contabo
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestExpandFirewallRulePresets(t *testing.T) {
+	cidrGroups := []interface{}{
+		map[string]interface{}{
+			"name": "office",
+			"ipv4": []interface{}{"194.165.134.20"},
+			"ipv6": []interface{}{},
+		},
+	}
+	ruleBlocks := []interface{}{
+		map[string]interface{}{
+			"service":     "ssh",
+			"cidr_groups": []interface{}{"office", "private"},
+			"ipv4":        []interface{}{"10.0.0.1"},
+			"ipv6":        []interface{}{},
+			"status":      "active",
+		},
+		map[string]interface{}{
+			"service":     "icmp",
+			"cidr_groups": []interface{}{"any"},
+			"ipv4":        []interface{}{},
+			"ipv6":        []interface{}{},
+			"status":      "inactive",
+		},
+	}
+
+	rules, err := expandFirewallRulePresets(ruleBlocks, cidrGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	ssh := rules[0].(map[string]interface{})
+	if ssh["protocol"] != "tcp" || ssh["action"] != "accept" || ssh["status"] != "active" {
+		t.Errorf("unexpected ssh rule %v", ssh)
+	}
+	if ports := stringsOf(ssh["dest_ports"]); len(ports) != 1 || ports[0] != "22" {
+		t.Errorf("unexpected ssh ports %v", ports)
+	}
+	srcCidr := ssh["src_cidr"].([]interface{})[0].(map[string]interface{})
+	if ipv4 := stringsOf(srcCidr["ipv4"]); len(ipv4) != 5 {
+		t.Errorf("expected the office, private and additional networks, got %v", ipv4)
+	}
+	if ipv6 := stringsOf(srcCidr["ipv6"]); len(ipv6) != 1 || ipv6[0] != "fc00::/7" {
+		t.Errorf("unexpected ssh ipv6 networks %v", ipv6)
+	}
+
+	icmp := rules[1].(map[string]interface{})
+	if icmp["protocol"] != "icmp" || icmp["status"] != "inactive" || len(stringsOf(icmp["dest_ports"])) != 0 {
+		t.Errorf("unexpected icmp rule %v", icmp)
+	}
+}
+
+func TestExpandFirewallRulePresetsErrors(t *testing.T) {
+	rule := func(cidrGroups ...interface{}) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"service":     "https",
+				"cidr_groups": cidrGroups,
+				"ipv4":        []interface{}{},
+				"ipv6":        []interface{}{},
+				"status":      "active",
+			},
+		}
+	}
+	group := func(name string) interface{} {
+		return map[string]interface{}{
+			"name": name,
+			"ipv4": []interface{}{"10.0.0.1"},
+			"ipv6": []interface{}{},
+		}
+	}
+
+	cases := map[string]struct {
+		ruleBlocks      []interface{}
+		cidrGroupBlocks []interface{}
+	}{
+		"no source":          {rule(), nil},
+		"unknown group":      {rule("office"), nil},
+		"redefined built in": {rule("any"), []interface{}{group("any")}},
+		"defined twice":      {rule("office"), []interface{}{group("office"), group("office")}},
+	}
+	for name, c := range cases {
+		if _, err := expandFirewallRulePresets(c.ruleBlocks, c.cidrGroupBlocks); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAccContaboFirewallRulesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboFirewallRulesConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.#", "3"),
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.0.protocol", "tcp"),
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.0.dest_ports.0", "22"),
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.0.src_cidr.0.ipv4.0", "194.165.134.20/32"),
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.2.dest_ports.0", "443"),
+					resource.TestCheckResourceAttr("data.contabo_firewall_rules.web", "rules.2.src_cidr.0.ipv6.0", "::/0"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckContaboFirewallRulesConfigBasic() string {
+	return `
+		provider "contabo" {}
+
+		data "contabo_firewall_rules" "web" {
+			cidr_group {
+				name = "office"
+				ipv4 = ["194.165.134.20"]
+			}
+
+			rule {
+				service     = "ssh"
+				cidr_groups = ["office"]
+			}
+			rule {
+				service     = "http"
+				cidr_groups = ["any"]
+			}
+			rule {
+				service     = "https"
+				cidr_groups = ["any"]
+			}
+		}
+	`
+}
@@ -0,0 +1,58 @@
+package contabo
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccContaboFirewallDataSourceByName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboFirewallDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.contabo_firewall.by_name", "id", "contabo_firewall.lookup", "id"),
+					resource.TestCheckResourceAttr("data.contabo_firewall.by_name", "status", "active"),
+					resource.TestCheckResourceAttr("data.contabo_firewalls.by_regex", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.contabo_firewalls.by_regex", "ids.0", "contabo_firewall.lookup", "id"),
+					resource.TestCheckResourceAttr("data.contabo_firewalls.by_regex", "firewalls.0.rules.0.inbound.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckContaboFirewallDataSourceConfig() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_firewall" "lookup" {
+			name   = "terraform-firewall-lookup"
+			status = "active"
+			rules {
+				inbound {
+					protocol   = "tcp"
+					dest_ports = ["22"]
+					action     = "accept"
+					status     = "active"
+					src_cidr {
+						ipv4 = ["0.0.0.0/0"]
+					}
+				}
+			}
+		}
+
+		data "contabo_firewall" "by_name" {
+			name = contabo_firewall.lookup.name
+		}
+
+		data "contabo_firewalls" "by_regex" {
+			name_regex = "^terraform-firewall-lookup$"
+			status     = contabo_firewall.lookup.status
+		}
+	`
+}
@@ -0,0 +1,172 @@
+package contabo
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+func dataSourceFirewalls() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the firewalls matching all of the given filters. Every firewall has the same attributes as the `contabo_firewall` data source.",
+		ReadContext: dataSourceFirewallsRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the filters.",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return firewalls whose name matches this regular expression.",
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"active", "inactive"}, false),
+				Description:  "Only return firewalls with this status, `active` or `inactive`.",
+			},
+			"instance_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Only return firewalls the compute instance is assigned to.",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The identifiers of the matching firewalls.",
+			},
+			"firewalls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching firewalls ordered by their name.",
+				Elem: &schema.Resource{
+					Schema: firewallsElemSchema(),
+				},
+			},
+		},
+	}
+}
+
+// firewallsElemSchema returns the schema of the contabo_firewall data source
+// restricted to the attributes set by flattenFirewall.
+func firewallsElemSchema() map[string]*schema.Schema {
+	firewallSchema := dataSourceFirewall().Schema
+	elemSchema := make(map[string]*schema.Schema)
+	for key := range flattenFirewall(openapi.FirewallResponse{}) {
+		elemSchema[key] = computedOnlySchema(firewallSchema[key])
+	}
+	return elemSchema
+}
+
+func dataSourceFirewallsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	nameRegex := d.Get("name_regex").(string)
+	status := d.Get("status").(string)
+	instanceId, instanceIdSet := d.GetOk("instance_id")
+
+	var nameMatcher *regexp.Regexp
+	if nameRegex != "" {
+		nameMatcher = regexp.MustCompile(nameRegex)
+	}
+
+	firewalls, diags := retrieveAllFirewalls(ctx, client, nil)
+	if diags.HasError() {
+		return diags
+	}
+
+	var matches []openapi.FirewallResponse
+	for _, firewall := range firewalls {
+		if nameMatcher != nil && !nameMatcher.MatchString(firewall.Name) {
+			continue
+		}
+		if status != "" && firewall.Status != status {
+			continue
+		}
+		if instanceIdSet && !firewallHasInstance(firewall, int64(instanceId.(int))) {
+			continue
+		}
+		matches = append(matches, firewall)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Name != matches[j].Name {
+			return matches[i].Name < matches[j].Name
+		}
+		return matches[i].FirewallId < matches[j].FirewallId
+	})
+
+	ids := make([]interface{}, 0, len(matches))
+	flattenedFirewalls := make([]interface{}, 0, len(matches))
+	for _, firewall := range matches {
+		ids = append(ids, firewall.FirewallId)
+		flattenedFirewalls = append(flattenedFirewalls, flattenFirewall(firewall))
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("firewalls", flattenedFirewalls); err != nil {
+		return diag.FromErr(err)
+	}
+	filters := []string{nameRegex, status}
+	if instanceIdSet {
+		filters = append(filters, strconv.Itoa(instanceId.(int)))
+	}
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(filters, "|"))))
+
+	return diags
+}
+
+// retrieveAllFirewalls pages through the firewalls of the account. filter
+// can be used to add query parameters to every page request.
+func retrieveAllFirewalls(
+	ctx context.Context,
+	client *openapi.APIClient,
+	filter func(openapi.ApiRetrieveFirewallListRequest) openapi.ApiRetrieveFirewallListRequest,
+) ([]openapi.FirewallResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var firewalls []openapi.FirewallResponse
+
+	for page := int64(1); ; page++ {
+		request := client.FirewallsApi.
+			RetrieveFirewallList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize)
+		if filter != nil {
+			request = filter(request)
+		}
+
+		res, httpResp, err := request.Execute()
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		firewalls = append(firewalls, res.Data...)
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return firewalls, diags
+		}
+	}
+}
+
+func firewallHasInstance(firewall openapi.FirewallResponse, instanceId int64) bool {
+	for _, instance := range firewall.Instances {
+		if instance.InstanceId == instanceId {
+			return true
+		}
+	}
+	return false
+}
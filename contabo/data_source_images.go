@@ -0,0 +1,211 @@
+package contabo
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+func dataSourceImages() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the standard and custom images matching all of the given filters, e.g. to look up the latest Ubuntu image instead of hardcoding its id.",
+		ReadContext: dataSourceImagesRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the filters.",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return images whose name matches this regular expression, e.g. `^ubuntu`.",
+			},
+			"os_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Linux", "Windows"}, true),
+				Description:  "Only return images of this type of operating system: `Linux` or `Windows`.",
+			},
+			"version_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return images whose version matches this regular expression, e.g. `^22\\.04`.",
+			},
+			"standard_image": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return standard images (`true`) or custom images (`false`). Both are returned if unset.",
+			},
+			"most_recent": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only return the most recently created image. Fails if no image matches the filters.",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The identifiers of the matching images.",
+			},
+			"images": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching images, the most recently created first.",
+				Elem: &schema.Resource{
+					Schema: imagesElemSchema(),
+				},
+			},
+		},
+	}
+}
+
+// imagesElemSchema returns the schema of the contabo_image data source
+// restricted to the attributes set by flattenImage.
+func imagesElemSchema() map[string]*schema.Schema {
+	imageSchema := dataSourceImage().Schema
+	elemSchema := make(map[string]*schema.Schema)
+	for key := range flattenImage(apiClient.ImageResponse{}) {
+		elemSchema[key] = computedOnlySchema(imageSchema[key])
+	}
+	return elemSchema
+}
+
+func dataSourceImagesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*apiClient.APIClient)
+
+	nameRegex := d.Get("name_regex").(string)
+	osType := d.Get("os_type").(string)
+	versionRegex := d.Get("version_regex").(string)
+	mostRecent := d.Get("most_recent").(bool)
+	standardImage, filterStandardImage := d.GetOkExists("standard_image")
+
+	images, diags := retrieveAllImages(ctx, client, func(request apiClient.ApiRetrieveImageListRequest) apiClient.ApiRetrieveImageListRequest {
+		if filterStandardImage {
+			request = request.StandardImage(standardImage.(bool))
+		}
+		return request
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	matches := filterImages(images, nameRegex, osType, versionRegex, mostRecent)
+	if mostRecent && len(matches) == 0 {
+		return HandleMissingDataObjectsFilters(
+			diags,
+			"No image found",
+			"No image matches the given filters, please adjust them.",
+		)
+	}
+
+	ids := make([]interface{}, 0, len(matches))
+	flattenedImages := make([]interface{}, 0, len(matches))
+	for _, image := range matches {
+		ids = append(ids, image.ImageId)
+		flattenedImages = append(flattenedImages, flattenImage(image))
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("images", flattenedImages); err != nil {
+		return diag.FromErr(err)
+	}
+	filters := []string{nameRegex, strings.ToLower(osType), versionRegex, strconv.FormatBool(mostRecent)}
+	if filterStandardImage {
+		filters = append(filters, strconv.FormatBool(standardImage.(bool)))
+	}
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(filters, "|"))))
+
+	return diags
+}
+
+// filterImages returns the images matching all filters, the most recently
+// created first. If mostRecent is set at most one image is returned. The API
+// only filters by exact name, regular expressions are matched here.
+func filterImages(images []apiClient.ImageResponse, nameRegex string, osType string, versionRegex string, mostRecent bool) []apiClient.ImageResponse {
+	nameMatcher := regexp.MustCompile(nameRegex)
+	versionMatcher := regexp.MustCompile(versionRegex)
+	var matches []apiClient.ImageResponse
+	for _, image := range images {
+		if !nameMatcher.MatchString(image.Name) || !versionMatcher.MatchString(image.Version) {
+			continue
+		}
+		if osType != "" && !strings.EqualFold(image.OsType, osType) {
+			continue
+		}
+		matches = append(matches, image)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CreationDate.After(matches[j].CreationDate)
+	})
+
+	if mostRecent && len(matches) > 1 {
+		matches = matches[:1]
+	}
+	return matches
+}
+
+// retrieveAllImages pages through the standard and custom images.
+// filter can be used to add query parameters to every page request.
+func retrieveAllImages(
+	ctx context.Context,
+	client *apiClient.APIClient,
+	filter func(apiClient.ApiRetrieveImageListRequest) apiClient.ApiRetrieveImageListRequest,
+) ([]apiClient.ImageResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var images []apiClient.ImageResponse
+
+	for page := int64(1); ; page++ {
+		request := client.ImagesApi.
+			RetrieveImageList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize)
+		if filter != nil {
+			request = filter(request)
+		}
+
+		res, httpResp, err := request.Execute()
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		images = append(images, res.Data...)
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return images, diags
+		}
+	}
+}
+
+// flattenImage returns the attributes of the contabo_image data source.
+func flattenImage(image apiClient.ImageResponse) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               image.ImageId,
+		"name":             image.Name,
+		"description":      image.Description,
+		"uploaded_size_mb": int(image.UploadedSizeMb),
+		"os_type":          image.OsType,
+		"version":          image.Version,
+		"format":           image.Format,
+		"status":           image.Status,
+		"error_message":    image.ErrorMessage,
+		"standard_image":   image.StandardImage,
+		"creation_date":    image.CreationDate.Format(time.RFC850),
+	}
+}
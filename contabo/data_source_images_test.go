@@ -0,0 +1,46 @@
+package contabo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	apiClient "contabo.com/openapi"
+)
+
+func TestFilterImages(t *testing.T) {
+	images := []apiClient.ImageResponse{
+		{ImageId: "ubuntu-20.04", Name: "ubuntu-20.04", OsType: "Linux", Version: "20.04", CreationDate: time.Date(2020, 4, 23, 0, 0, 0, 0, time.UTC)},
+		{ImageId: "ubuntu-22.04", Name: "ubuntu-22.04", OsType: "Linux", Version: "22.04", CreationDate: time.Date(2022, 4, 21, 0, 0, 0, 0, time.UTC)},
+		{ImageId: "ubuntu-22.04-custom", Name: "my-ubuntu", OsType: "Linux", Version: "22.04", CreationDate: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{ImageId: "windows-2022", Name: "windows-server-2022", OsType: "Windows", Version: "2022", CreationDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	cases := []struct {
+		name         string
+		nameRegex    string
+		osType       string
+		versionRegex string
+		mostRecent   bool
+		ids          []string
+	}{
+		{name: "no filters", ids: []string{"ubuntu-22.04-custom", "windows-2022", "ubuntu-22.04", "ubuntu-20.04"}},
+		{name: "name regex", nameRegex: "^ubuntu", ids: []string{"ubuntu-22.04", "ubuntu-20.04"}},
+		{name: "name regex matches anywhere", nameRegex: "ubuntu", ids: []string{"ubuntu-22.04-custom", "ubuntu-22.04", "ubuntu-20.04"}},
+		{name: "version regex", versionRegex: `^22\.04$`, ids: []string{"ubuntu-22.04-custom", "ubuntu-22.04"}},
+		{name: "os type ignores case", osType: "windows", ids: []string{"windows-2022"}},
+		{name: "all filters", nameRegex: "^ubuntu", osType: "Linux", versionRegex: `^22\.`, ids: []string{"ubuntu-22.04"}},
+		{name: "no match", nameRegex: "^debian", ids: nil},
+		{name: "most recent", osType: "Linux", mostRecent: true, ids: []string{"ubuntu-22.04-custom"}},
+		{name: "most recent with filters", nameRegex: "^ubuntu", mostRecent: true, ids: []string{"ubuntu-22.04"}},
+		{name: "most recent without match", nameRegex: "^debian", mostRecent: true, ids: nil},
+	}
+	for _, c := range cases {
+		var ids []string
+		for _, image := range filterImages(images, c.nameRegex, c.osType, c.versionRegex, c.mostRecent) {
+			ids = append(ids, image.ImageId)
+		}
+		if !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("%s: filterImages() = %v, want %v", c.name, ids, c.ids)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package contabo
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 
 	apiClient "contabo.com/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,8 +19,9 @@ func dataSourceInstance() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The identifier of the compute instance. Use it to manage it!",
+				Optional:    true,
+				Computed:    true,
+				Description: "The identifier of the compute instance. Use it to manage it! If it is set, `name` and `display_name` are not used to look up the compute instance.",
 			},
 			"last_updated": {
 				Type:        schema.TypeString,
@@ -27,14 +30,15 @@ func dataSourceInstance() *schema.Resource {
 			},
 			"name": {
 				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
-				Description: "Name of the compute instance.",
+				Description: "Name of the compute instance. Can be used instead of `id` to look up the compute instance. If both `name` and `display_name` are set, the compute instance has to match both.",
 			},
 			"display_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Image Id is used to set up the compute instance. Ubuntu 20.04 is the default.",
+				Description: "The instance name chosen by the customer that will be shown in the customer panel. Can be used instead of `id` to look up the compute instance, it has to be unique.",
 			},
 			"image_id": {
 				Type:        schema.TypeString,
@@ -153,6 +157,16 @@ func dataSourceInstance() *schema.Resource {
 				Computed:    true,
 				Description: "The date on which the instance will be cancelled.",
 			},
+			"scheduled_cancel_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date on which the instance is going to be cancelled. Empty if no cancellation is pending.",
+			},
+			"cancellation_pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the instance has been cancelled and will vanish at `scheduled_cancel_date`.",
+			},
 			"status": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -217,10 +231,90 @@ func dataSourceInstance() *schema.Resource {
 				Computed:    true,
 				Description: "Initial contract period in months. Available periods are: 1, 3, 6 and 12 months. The default setting is 1 month.",
 			},
+			"contract_start_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date (`YYYY-MM-DD`) on which the contract of the compute instance started.",
+			},
+			"contract_end_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date (`YYYY-MM-DD`) on which the instance is going to be cancelled. Empty if no cancellation is pending, as the contract period is not available for instances which are not managed by terraform.",
+			},
+			"next_billing_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Always empty, as the contract period is not available for instances which are not managed by terraform.",
+			},
+			"ipv4_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The primary IPv4 address of the instance, same as `ip_config[0].v4[0].ip`.",
+			},
+			"ipv6_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The primary IPv6 address of the instance, same as `ip_config[0].v6[0].ip`.",
+			},
+			"additional_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "All other additional IP addresses of the instance. Every entry holds either an additional IPv4 address or an additional IPv6 network.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"v4": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ip": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "IP Address",
+									},
+									"netmask_cidr": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Netmask CIDR",
+									},
+									"gateway": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Gateway",
+									},
+								},
+							},
+						},
+						"v6": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ip": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "IP Address",
+									},
+									"netmask_cidr": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Netmask CIDR",
+									},
+									"gateway": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Gateway",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			"additional_ips_v4": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "All other additional IP addresses of the instance.",
+				Description: "All other additional IPv4 addresses of the instance.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"ip": {
@@ -256,8 +350,17 @@ func dataSourceInstanceRead(
 	var instanceId int64
 	var err error
 	id := d.Get("id").(string)
+	name := d.Get("name").(string)
+	displayName := d.Get("display_name").(string)
 	if id != "" {
 		instanceId, err = strconv.ParseInt(id, 10, 64)
+	} else if name != "" || displayName != "" {
+		instanceId, diags = findInstanceByName(ctx, client, name, displayName)
+		if diags.HasError() {
+			return diags
+		}
+	} else {
+		return HandleMissingDataObjectsFilters(diags, "No instance given", "One of id, name or display_name has to be set.")
 	}
 
 	if err != nil {
@@ -277,9 +380,84 @@ func dataSourceInstanceRead(
 
 	d.SetId(strconv.Itoa(int(res.Data[0].InstanceId)))
 
-	return AddInstanceToData(
+	if err := d.Set("cancel_date", res.Data[0].CancelDate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags = AddInstanceToData(
 		res.Data[0],
 		d,
 		diags,
 	)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := d.Set("additional_ips_v4", buildAdditionalIpsV4(res.Data[0].AdditionalIps)); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+// findInstanceByName returns the id of the only instance with the given name
+// and display name. Empty values are not compared.
+func findInstanceByName(ctx context.Context, client *apiClient.APIClient, name string, displayName string) (int64, diag.Diagnostics) {
+	instances, diags := retrieveAllInstances(ctx, client, func(request apiClient.ApiRetrieveInstancesListRequest) apiClient.ApiRetrieveInstancesListRequest {
+		if name != "" {
+			request = request.Name(name)
+		}
+		if displayName != "" {
+			request = request.DisplayName(displayName)
+		}
+		return request
+	})
+	if diags.HasError() {
+		return 0, diags
+	}
+
+	matches := matchInstancesByName(instances, name, displayName)
+	var lookups []string
+	if name != "" {
+		lookups = append(lookups, fmt.Sprintf("name %q", name))
+	}
+	if displayName != "" {
+		lookups = append(lookups, fmt.Sprintf("display name %q", displayName))
+	}
+	lookup := strings.Join(lookups, " and ")
+	if len(matches) == 0 {
+		return 0, HandleMissingDataObjectsFilters(diags, "No instance found", fmt.Sprintf("There is no instance with the %s.", lookup))
+	} else if len(matches) > 1 {
+		return 0, HandleMissingDataObjectsFilters(diags, "Multiple instances found", fmt.Sprintf("There are %d instances with the %s, please use id instead.", len(matches), lookup))
+	}
+	return matches[0], diags
+}
+
+// matchInstancesByName returns the ids of the instances with exactly the given
+// name and display name, the list endpoint also returns partial matches.
+func matchInstancesByName(instances []apiClient.ListInstancesResponseData, name string, displayName string) []int64 {
+	var matches []int64
+	for _, instance := range instances {
+		if name != "" && instance.Name != name {
+			continue
+		}
+		if displayName != "" && instance.DisplayName != displayName {
+			continue
+		}
+		matches = append(matches, instance.InstanceId)
+	}
+	return matches
+}
+
+func buildAdditionalIpsV4(additionalIpsResponse []apiClient.AdditionalIp) []map[string]interface{} {
+	additionalIpsV4 := []map[string]interface{}{}
+	for _, additionalIp := range additionalIpsResponse {
+		if ipV4, ok := additionalIp.GetV4Ok(); ok && ipV4.Ip != "" {
+			additionalIpsV4 = append(additionalIpsV4, map[string]interface{}{
+				"ip":           ipV4.Ip,
+				"netmask_cidr": ipV4.NetmaskCidr,
+				"gateway":      ipV4.Gateway,
+			})
+		}
+	}
+	return additionalIpsV4
 }
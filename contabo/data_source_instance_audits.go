@@ -0,0 +1,250 @@
+package contabo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+func dataSourceInstanceAudits() *schema.Resource {
+	return &schema.Resource{
+		Description: "The audit history of your compute instances, e.g. to find out who reinstalled an instance and when.",
+		ReadContext: dataSourceInstanceAuditsRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the filters.",
+			},
+			"instance_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return audit entries of this compute instance.",
+			},
+			"changed_by": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return audit entries of changes made by this user id.",
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(instanceAuditActions, true),
+				Description:  "Only return audit entries of this action: " + instanceAuditActionsDescription + ".",
+			},
+			"start_date": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDate,
+				Description:  "Only return audit entries from this date (`YYYY-MM-DD`) on. Dates are compared in UTC.",
+			},
+			"end_date": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDate,
+				Description:  "Only return audit entries up to and including this date (`YYYY-MM-DD`). Dates are compared in UTC.",
+			},
+			"audits": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The audit entries matching the filters.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The identifier of the audit entry.",
+						},
+						"instance_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The identifier of the compute instance.",
+						},
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The action which has been performed: " + instanceAuditActionsDescription + ".",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the action has been performed (RFC 3339).",
+						},
+						"changed_by": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user id which performed the action.",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the user which performed the action.",
+						},
+						"request_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The request id of the action.",
+						},
+						"trace_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The trace id of the action.",
+						},
+						"changes": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The changes made by the action as JSON, use `jsondecode` to access them.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceInstanceAuditsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*apiClient.APIClient)
+
+	filter := instanceAuditFilter{
+		instanceId: d.Get("instance_id").(string),
+		changedBy:  d.Get("changed_by").(string),
+		action:     d.Get("action").(string),
+		startDate:  d.Get("start_date").(string),
+		endDate:    d.Get("end_date").(string),
+	}
+	if err := filter.validate(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var instanceId64 int64
+	if filter.instanceId != "" {
+		var err error
+		instanceId64, err = strconv.ParseInt(filter.instanceId, 10, 64)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	audits := []interface{}{}
+	for page := int64(1); ; page++ {
+		request := client.InstancesAuditsApi.
+			RetrieveInstancesAuditsList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize)
+		if filter.instanceId != "" {
+			request = request.InstanceId(instanceId64)
+		}
+		if filter.changedBy != "" {
+			request = request.ChangedBy(filter.changedBy)
+		}
+		if filter.startDate != "" {
+			request = request.StartDate(filter.startDate)
+		}
+		if filter.endDate != "" {
+			request = request.EndDate(filter.endDate)
+		}
+
+		res, httpResp, err := request.Execute()
+		if err != nil {
+			return HandleResponseErrors(diags, httpResp)
+		}
+
+		for _, audit := range res.Data {
+			// the API can not filter by action and its date range might
+			// differ by a day depending on the time zone
+			if !filter.matches(audit.InstanceId, audit.ChangedBy, audit.Action, audit.Timestamp) {
+				continue
+			}
+			changes, err := json.Marshal(audit.Changes)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			audits = append(audits, map[string]interface{}{
+				"id":          strconv.FormatInt(audit.Id, 10),
+				"instance_id": strconv.FormatInt(audit.InstanceId, 10),
+				"action":      audit.Action,
+				"timestamp":   audit.Timestamp.Format(time.RFC3339),
+				"changed_by":  audit.ChangedBy,
+				"username":    audit.Username,
+				"request_id":  audit.RequestId,
+				"trace_id":    audit.TraceId,
+				"changes":     string(changes),
+			})
+		}
+
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			break
+		}
+	}
+
+	if err := d.Set("audits", audits); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(filter.id())
+
+	return diags
+}
+
+// instanceAuditActions are the values of the action enum of
+// InstancesAuditResponse in the API specification. The generated client
+// declares the action as a plain string without constants.
+var instanceAuditActions = []string{"CREATED", "CHANGED", "DELETED"}
+
+const instanceAuditActionsDescription = "`CREATED`, `CHANGED` or `DELETED`"
+
+// instanceAuditFilter holds the filters of contabo_instance_audits. Empty
+// filters match every audit entry.
+type instanceAuditFilter struct {
+	instanceId string
+	changedBy  string
+	action     string
+	startDate  string
+	endDate    string
+}
+
+func (filter instanceAuditFilter) validate() error {
+	if filter.startDate != "" && filter.endDate != "" && filter.startDate > filter.endDate {
+		return fmt.Errorf("start_date %s is after end_date %s", filter.startDate, filter.endDate)
+	}
+	return nil
+}
+
+// matches tells whether an audit entry matches all filters. Dates are
+// compared by the day of the timestamp in UTC, both ends of the range are
+// included.
+func (filter instanceAuditFilter) matches(instanceId int64, changedBy string, action string, timestamp time.Time) bool {
+	if filter.instanceId != "" && strconv.FormatInt(instanceId, 10) != filter.instanceId {
+		return false
+	}
+	if filter.changedBy != "" && changedBy != filter.changedBy {
+		return false
+	}
+	if filter.action != "" && !strings.EqualFold(action, filter.action) {
+		return false
+	}
+	day := timestamp.UTC().Format(dateLayout)
+	if filter.startDate != "" && day < filter.startDate {
+		return false
+	}
+	if filter.endDate != "" && day > filter.endDate {
+		return false
+	}
+	return true
+}
+
+// id returns a hash of the filters.
+func (filter instanceAuditFilter) id() string {
+	return strconv.Itoa(schema.HashString(strings.Join([]string{filter.instanceId, filter.changedBy, strings.ToUpper(filter.action), filter.startDate, filter.endDate}, "|")))
+}
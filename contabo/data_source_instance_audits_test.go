@@ -0,0 +1,89 @@
+package contabo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInstanceAuditFilterMatches(t *testing.T) {
+	timestamp := time.Date(2022, 3, 14, 23, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		filter    instanceAuditFilter
+		timestamp time.Time
+		matches   bool
+	}{
+		{name: "no filters", matches: true},
+		{name: "instance", filter: instanceAuditFilter{instanceId: "100"}, matches: true},
+		{name: "other instance", filter: instanceAuditFilter{instanceId: "101"}, matches: false},
+		{name: "user", filter: instanceAuditFilter{changedBy: "user-1"}, matches: true},
+		{name: "other user", filter: instanceAuditFilter{changedBy: "user-2"}, matches: false},
+		{name: "action", filter: instanceAuditFilter{action: "CHANGED"}, matches: true},
+		{name: "action ignores case", filter: instanceAuditFilter{action: "changed"}, matches: true},
+		{name: "other action", filter: instanceAuditFilter{action: "CREATED"}, matches: false},
+		{name: "range", filter: instanceAuditFilter{startDate: "2022-03-01", endDate: "2022-03-31"}, matches: true},
+		{name: "start date is included", filter: instanceAuditFilter{startDate: "2022-03-14"}, matches: true},
+		{name: "end date is included", filter: instanceAuditFilter{endDate: "2022-03-14"}, matches: true},
+		{name: "before start date", filter: instanceAuditFilter{startDate: "2022-03-15"}, matches: false},
+		{name: "after end date", filter: instanceAuditFilter{endDate: "2022-03-13"}, matches: false},
+		{name: "dates are compared in UTC", filter: instanceAuditFilter{endDate: "2022-03-14"}, timestamp: time.Date(2022, 3, 15, 0, 30, 0, 0, time.FixedZone("CET", 3600)), matches: true},
+		{name: "all filters", filter: instanceAuditFilter{instanceId: "100", changedBy: "user-1", action: "Changed", startDate: "2022-03-14", endDate: "2022-03-14"}, matches: true},
+		{name: "one filter fails", filter: instanceAuditFilter{instanceId: "100", changedBy: "user-2", action: "CHANGED"}, matches: false},
+	}
+	for _, c := range cases {
+		auditTimestamp := timestamp
+		if !c.timestamp.IsZero() {
+			auditTimestamp = c.timestamp
+		}
+		if matches := c.filter.matches(100, "user-1", "CHANGED", auditTimestamp); matches != c.matches {
+			t.Errorf("%s: matches() = %t, want %t", c.name, matches, c.matches)
+		}
+	}
+}
+
+func TestInstanceAuditFilterValidate(t *testing.T) {
+	cases := []struct {
+		filter instanceAuditFilter
+		valid  bool
+	}{
+		{filter: instanceAuditFilter{}, valid: true},
+		{filter: instanceAuditFilter{startDate: "2022-03-14"}, valid: true},
+		{filter: instanceAuditFilter{endDate: "2022-03-14"}, valid: true},
+		{filter: instanceAuditFilter{startDate: "2022-03-14", endDate: "2022-03-14"}, valid: true},
+		{filter: instanceAuditFilter{startDate: "2022-03-15", endDate: "2022-03-14"}, valid: false},
+	}
+	for _, c := range cases {
+		if err := c.filter.validate(); c.valid != (err == nil) {
+			t.Errorf("%+v: validate() = %v, valid = %t", c.filter, err, c.valid)
+		}
+	}
+}
+
+func TestInstanceAuditFilterId(t *testing.T) {
+	filter := instanceAuditFilter{instanceId: "100", action: "changed"}
+	if filter.id() != (instanceAuditFilter{instanceId: "100", action: "CHANGED"}).id() {
+		t.Error("id() depends on the case of the action")
+	}
+	if filter.id() == (instanceAuditFilter{instanceId: "101", action: "CHANGED"}).id() {
+		t.Error("id() does not depend on the instance")
+	}
+}
+
+func TestInstanceAuditActionValidation(t *testing.T) {
+	validateAction := dataSourceInstanceAudits().Schema["action"].ValidateFunc
+	cases := []struct {
+		action string
+		valid  bool
+	}{
+		{action: "CREATED", valid: true},
+		{action: "changed", valid: true},
+		{action: "Deleted", valid: true},
+		{action: "UPDATE", valid: false},
+		{action: "UPDATED", valid: false},
+	}
+	for _, c := range cases {
+		if _, errs := validateAction(c.action, "action"); c.valid != (len(errs) == 0) {
+			t.Errorf("%s: validation errors %v, valid = %t", c.action, errs, c.valid)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package contabo
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+// instanceDetailsConcurrency is the number of instances whose details are
+// retrieved at the same time.
+const instanceDetailsConcurrency = 5
+
+func dataSourceInstances() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the compute instances matching all of the given filters. Every instance has the same attributes as the `contabo_instance` data source.",
+		ReadContext: dataSourceInstancesRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A hash of the filters.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances whose name contains this value.",
+			},
+			"display_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances whose display name contains this value.",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances in this region, e.g. `EU`.",
+			},
+			"data_center": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances in this data center.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances with this status, e.g. `running`.",
+			},
+			"product_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only return instances of one of these products, e.g. `[\"V45\", \"V47\"]`.",
+			},
+			"product_types": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only return instances of one of these product types: `hdd`, `ssd`, `vds`, `nvme`.",
+			},
+			"tag_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return instances which are assigned to this tag.",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The identifiers of the matching instances.",
+			},
+			"instances": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching instances ordered by their identifier.",
+				Elem: &schema.Resource{
+					Schema: instancesElemSchema(),
+				},
+			},
+		},
+	}
+}
+
+// instancesElemSchema returns the schema of the contabo_instance data source
+// restricted to the attributes set by flattenInstance.
+func instancesElemSchema() map[string]*schema.Schema {
+	instanceSchema := dataSourceInstance().Schema
+	elemSchema := make(map[string]*schema.Schema)
+	for key := range flattenInstance(apiClient.InstanceResponse{}) {
+		elemSchema[key] = computedOnlySchema(instanceSchema[key])
+	}
+	return elemSchema
+}
+
+// computedOnlySchema returns a copy of an attribute schema which can be used
+// within a computed attribute.
+func computedOnlySchema(attributeSchema *schema.Schema) *schema.Schema {
+	computed := &schema.Schema{
+		Type:        attributeSchema.Type,
+		Computed:    true,
+		Description: attributeSchema.Description,
+		Elem:        attributeSchema.Elem,
+	}
+	if elem, ok := attributeSchema.Elem.(*schema.Resource); ok {
+		elemSchema := make(map[string]*schema.Schema)
+		for key, value := range elem.Schema {
+			elemSchema[key] = computedOnlySchema(value)
+		}
+		computed.Elem = &schema.Resource{Schema: elemSchema}
+	}
+	return computed
+}
+
+func dataSourceInstancesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*apiClient.APIClient)
+
+	name := d.Get("name").(string)
+	displayName := d.Get("display_name").(string)
+	region := d.Get("region").(string)
+	dataCenter := d.Get("data_center").(string)
+	status := d.Get("status").(string)
+	productIds := joinStrings(d.Get("product_ids").([]interface{}))
+	productTypes := joinStrings(d.Get("product_types").([]interface{}))
+	tagId := d.Get("tag_id").(string)
+
+	instances, diags := retrieveAllInstances(ctx, client, func(request apiClient.ApiRetrieveInstancesListRequest) apiClient.ApiRetrieveInstancesListRequest {
+		if name != "" {
+			request = request.Name(name)
+		}
+		if displayName != "" {
+			request = request.DisplayName(displayName)
+		}
+		if region != "" {
+			request = request.Region(region)
+		}
+		if dataCenter != "" {
+			request = request.DataCenter(dataCenter)
+		}
+		if status != "" {
+			request = request.Status(status)
+		}
+		if productIds != "" {
+			request = request.ProductIds(productIds)
+		}
+		if productTypes != "" {
+			request = request.ProductTypes(productTypes)
+		}
+		return request
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	var taggedInstanceIds map[string]bool
+	if tagId != "" {
+		taggedInstanceIds, diags = retrieveTaggedInstanceIds(ctx, client, tagId)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	instanceIds := selectInstanceIds(instances, taggedInstanceIds)
+
+	// the list endpoint returns less details than the instance endpoint,
+	// instances cancelled in the meantime are left out
+	details := make([]map[string]interface{}, len(instanceIds))
+	positions := make([]int, len(instanceIds))
+	for position := range positions {
+		positions[position] = position
+	}
+	diags = runInBatches(positions, instanceDetailsConcurrency, func(position int) diag.Diagnostics {
+		var diags diag.Diagnostics
+		res, httpResp, err := client.InstancesApi.
+			RetrieveInstance(ctx, instanceIds[position]).
+			XRequestId(uuid.NewV4().String()).
+			Execute()
+
+		if err != nil {
+			if isNotFound(httpResp) {
+				return diags
+			}
+			return HandleResponseErrors(diags, httpResp)
+		} else if len(res.Data) != 1 {
+			return MultipleDataObjectsError(diags)
+		}
+		details[position] = flattenInstance(res.Data[0])
+		return diags
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	ids := make([]interface{}, 0, len(instanceIds))
+	flattenedInstances := make([]interface{}, 0, len(instanceIds))
+	for position, instanceId := range instanceIds {
+		if details[position] == nil {
+			continue
+		}
+		ids = append(ids, strconv.FormatInt(instanceId, 10))
+		flattenedInstances = append(flattenedInstances, details[position])
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("instances", flattenedInstances); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join([]string{name, displayName, region, dataCenter, status, productIds, productTypes, tagId}, "|"))))
+
+	return diags
+}
+
+// selectInstanceIds returns the sorted ids of the listed instances. If
+// taggedInstanceIds is not nil only the instances in it are returned.
+func selectInstanceIds(instances []apiClient.ListInstancesResponseData, taggedInstanceIds map[string]bool) []int64 {
+	var instanceIds []int64
+	for _, instance := range instances {
+		if taggedInstanceIds != nil && !taggedInstanceIds[strconv.FormatInt(instance.InstanceId, 10)] {
+			continue
+		}
+		instanceIds = append(instanceIds, instance.InstanceId)
+	}
+	sort.Slice(instanceIds, func(i, j int) bool {
+		return instanceIds[i] < instanceIds[j]
+	})
+	return instanceIds
+}
+
+func retrieveTaggedInstanceIds(ctx context.Context, client *apiClient.APIClient, tagId string) (map[string]bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tagId64, err := strconv.ParseInt(tagId, 10, 64)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	instanceIds := make(map[string]bool)
+	for page := int64(1); ; page++ {
+		res, httpResp, err := client.TagAssignmentsApi.
+			RetrieveAssignmentList(ctx, tagId64).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize).
+			ResourceType("instance").
+			Execute()
+
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		for _, assignment := range res.Data {
+			instanceIds[assignment.ResourceId] = true
+		}
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return instanceIds, diags
+		}
+	}
+}
+
+func joinStrings(values []interface{}) string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, value.(string))
+	}
+	return strings.Join(strs, ",")
+}
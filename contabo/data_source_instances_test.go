@@ -0,0 +1,57 @@
+package contabo
+
+import (
+	"reflect"
+	"testing"
+
+	apiClient "contabo.com/openapi"
+)
+
+func testInstancesList() []apiClient.ListInstancesResponseData {
+	return []apiClient.ListInstancesResponseData{
+		{InstanceId: 300, Name: "vmi300", DisplayName: "web"},
+		{InstanceId: 100, Name: "vmi100", DisplayName: "web-2"},
+		{InstanceId: 200, Name: "vmi1000", DisplayName: "web"},
+		{InstanceId: 400, Name: "vmi10", DisplayName: ""},
+	}
+}
+
+func TestMatchInstancesByName(t *testing.T) {
+	cases := []struct {
+		name        string
+		displayName string
+		matches     []int64
+	}{
+		{name: "vmi100", matches: []int64{100}},
+		{name: "vmi10", matches: []int64{400}},
+		{name: "vmi1", matches: nil},
+		{displayName: "web", matches: []int64{300, 200}},
+		{displayName: "web-2", matches: []int64{100}},
+		{displayName: "we", matches: nil},
+		{name: "vmi300", displayName: "web", matches: []int64{300}},
+		{name: "vmi100", displayName: "web", matches: nil},
+	}
+	for _, c := range cases {
+		if matches := matchInstancesByName(testInstancesList(), c.name, c.displayName); !reflect.DeepEqual(matches, c.matches) {
+			t.Errorf("matchInstancesByName(%q, %q) = %v, want %v", c.name, c.displayName, matches, c.matches)
+		}
+	}
+}
+
+func TestSelectInstanceIds(t *testing.T) {
+	cases := []struct {
+		name   string
+		tagged map[string]bool
+		ids    []int64
+	}{
+		{name: "no tag filter", tagged: nil, ids: []int64{100, 200, 300, 400}},
+		{name: "tagged instances", tagged: map[string]bool{"300": true, "100": true}, ids: []int64{100, 300}},
+		{name: "tagged instances which are not listed", tagged: map[string]bool{"500": true, "200": true}, ids: []int64{200}},
+		{name: "no tagged instances", tagged: map[string]bool{}, ids: nil},
+	}
+	for _, c := range cases {
+		if ids := selectInstanceIds(testInstancesList(), c.tagged); !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("%s: selectInstanceIds() = %v, want %v", c.name, ids, c.ids)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package contabo
+
+import (
+	"context"
+	"sort"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRegions() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the regions in which compute instances can be located, e.g. to validate the `region` of a compute instance.",
+		ReadContext: dataSourceRegionsRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Always `regions`.",
+			},
+			"slugs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The slugs of all regions.",
+			},
+			"regions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "All regions ordered by their slug.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"slug": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Slug of the region, use it as `region` of a compute instance.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the region.",
+						},
+						"data_centers": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The slugs of the data centers in the region.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRegionsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*apiClient.APIClient)
+
+	// the API has no region endpoint, every data center names its region
+	dataCenters, diags := retrieveAllDataCenters(ctx, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	regions := make(map[string]map[string]interface{})
+	var slugs []string
+	for _, dataCenter := range dataCenters {
+		region, ok := regions[dataCenter.RegionSlug]
+		if !ok {
+			region = map[string]interface{}{
+				"slug":         dataCenter.RegionSlug,
+				"name":         dataCenter.RegionName,
+				"data_centers": []string{},
+			}
+			regions[dataCenter.RegionSlug] = region
+			slugs = append(slugs, dataCenter.RegionSlug)
+		}
+		region["data_centers"] = append(region["data_centers"].([]string), dataCenter.Slug)
+	}
+	sort.Strings(slugs)
+
+	flattenedRegions := make([]interface{}, 0, len(slugs))
+	for _, slug := range slugs {
+		flattenedRegions = append(flattenedRegions, regions[slug])
+	}
+
+	if err := d.Set("slugs", slugs); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("regions", flattenedRegions); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("regions")
+
+	return diags
+}
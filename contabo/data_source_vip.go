@@ -0,0 +1,81 @@
+package contabo
+
+import (
+	"context"
+
+	apiClient "contabo.com/openapi"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+func dataSourceVip() *schema.Resource {
+	return &schema.Resource{
+		Description: "A VIP is an additional or floating IP address you purchased. It can be assigned to a compute instance in the same region with the `contabo_vip` resource.",
+		ReadContext: dataSourceVipRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP address of the VIP.",
+			},
+			"ip": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPAddress,
+				Description:  "The IP address of the VIP.",
+			},
+			"instance_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The identifier of the compute instance the VIP is assigned to. Empty if the VIP is not assigned to a compute instance.",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The region of the VIP.",
+			},
+			"data_center": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The data center of the VIP.",
+			},
+			"ip_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP version of the VIP, `v4` or `v6`.",
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the VIP, `additional` or `floating`.",
+			},
+		},
+	}
+}
+
+func dataSourceVipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*apiClient.APIClient)
+
+	ip := canonicalIpAddress(d.Get("ip").(string))
+
+	res, httpResp, err := client.VIPApi.
+		RetrieveVip(ctx, ip).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) == 0 {
+		return NoDataError(diags)
+	} else if len(res.Data) > 1 {
+		return MultipleDataObjectsError(diags)
+	}
+
+	d.SetId(ip)
+
+	return AddVipToData(res.Data[0], d, diags)
+}
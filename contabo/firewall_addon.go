@@ -0,0 +1,156 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+// setFirewallAddOn books the firewall add-on with an upgrade request. Like
+// private networking it is booked by sending an empty object.
+func setFirewallAddOn(upgradeInstanceRequest *openapi.UpgradeInstanceRequest) {
+	firewalling := make(map[string]interface{})
+	upgradeInstanceRequest.Firewalling = &firewalling
+}
+
+// firewallAddOnWarning reports the instances which have been upgraded with
+// the charged firewall add-on.
+func firewallAddOnWarning(upgradedInstanceIds []int) diag.Diagnostics {
+	if len(upgradedInstanceIds) == 0 {
+		return nil
+	}
+	sorted := append([]int(nil), upgradedInstanceIds...)
+	sort.Ints(sorted)
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Firewall add-on booked",
+		Detail:   fmt.Sprintf("The instances %v did not have the firewall add-on and have been upgraded with it. The add-on is charged with the instances.", sorted),
+	}}
+}
+
+// instanceHasFirewallAddOn tells whether the firewall add-on is booked for
+// an instance.
+func instanceHasFirewallAddOn(
+	ctx context.Context,
+	client *openapi.APIClient,
+	instanceId int64,
+) (bool, diag.Diagnostics) {
+	res, httpResp, err := client.InstancesApi.
+		RetrieveInstance(ctx, instanceId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if err != nil {
+		return false, HandleResponseErrors(nil, httpResp)
+	} else if len(res.Data) != 1 {
+		return false, MultipleDataObjectsError(nil)
+	}
+
+	for _, addOn := range res.Data[0].AddOns {
+		if addOn.Id == firewallNetworkAddOnId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// enableFirewallAddOn books the firewall add-on for an instance which does
+// not have it yet and tells whether it has been booked.
+func enableFirewallAddOn(
+	ctx context.Context,
+	client *openapi.APIClient,
+	instanceId int64,
+) (bool, diag.Diagnostics) {
+	hasAddOn, diags := instanceHasFirewallAddOn(ctx, client, instanceId)
+	if diags.HasError() || hasAddOn {
+		return false, diags
+	}
+
+	upgradeInstanceRequest := openapi.UpgradeInstanceRequest{}
+	setFirewallAddOn(&upgradeInstanceRequest)
+	_, httpResp, err := client.InstancesApi.
+		UpgradeInstance(ctx, instanceId).
+		XRequestId(uuid.NewV4().String()).
+		UpgradeInstanceRequest(upgradeInstanceRequest).
+		Execute()
+	if err != nil {
+		return false, HandleResponseErrors(diags, httpResp)
+	}
+	return true, diags
+}
+
+// planFirewallAddOns adds the instances which will be upgraded with the
+// firewall add-on to addon_instance_ids, so the charged change shows up in
+// the plan. Only the add-ons of added instances are retrieved. If they can
+// not be retrieved, e.g. because an added instance does not exist yet,
+// addon_instance_ids is unknown until the apply.
+func planFirewallAddOns(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("auto_enable_addon").(bool) || !d.HasChange("instance_ids") {
+		return nil
+	}
+	if !d.NewValueKnown("instance_ids") {
+		return d.SetNewComputed("addon_instance_ids")
+	}
+
+	old, new := d.GetChange("instance_ids")
+	addOnInstanceIds := d.Get("addon_instance_ids").(*schema.Set)
+	upgradedInstanceIds, err := instancesWithoutFirewallAddOn(old.(*schema.Set), new.(*schema.Set), addOnInstanceIds, func(instanceId int) (bool, error) {
+		hasAddOn, diags := instanceHasFirewallAddOn(ctx, m.(*openapi.APIClient), int64(instanceId))
+		if diags.HasError() {
+			return false, DiagnosticsToError(diags)
+		}
+		return hasAddOn, nil
+	})
+	if err != nil {
+		return d.SetNewComputed("addon_instance_ids")
+	}
+	if len(upgradedInstanceIds) == 0 {
+		return nil
+	}
+	return d.SetNew("addon_instance_ids", mergeInstanceIds(addOnInstanceIds, upgradedInstanceIds))
+}
+
+// instancesWithoutFirewallAddOn returns the sorted instances which are added
+// to a firewall and need the firewall add-on. Instances which are already
+// listed in addOnInstanceIds are not checked again.
+func instancesWithoutFirewallAddOn(
+	oldInstanceIds *schema.Set,
+	newInstanceIds *schema.Set,
+	addOnInstanceIds *schema.Set,
+	hasAddOn func(instanceId int) (bool, error),
+) ([]int, error) {
+	addedInstanceIds := instanceIdsOf(newInstanceIds.Difference(oldInstanceIds).List())
+	sort.Ints(addedInstanceIds)
+
+	var instanceIds []int
+	for _, instanceId := range addedInstanceIds {
+		if addOnInstanceIds.Contains(instanceId) {
+			continue
+		}
+		has, err := hasAddOn(instanceId)
+		if err != nil {
+			return nil, fmt.Errorf("could not check the add-ons of instance %d: %s", instanceId, err)
+		}
+		if !has {
+			instanceIds = append(instanceIds, instanceId)
+		}
+	}
+	return instanceIds, nil
+}
+
+// mergeInstanceIds returns the sorted union of a set of instance ids and
+// further instance ids.
+func mergeInstanceIds(instanceIds *schema.Set, moreInstanceIds []int) []int {
+	merged := instanceIdsOf(instanceIds.List())
+	for _, instanceId := range moreInstanceIds {
+		if !instanceIds.Contains(instanceId) {
+			merged = append(merged, instanceId)
+		}
+	}
+	sort.Ints(merged)
+	return merged
+}
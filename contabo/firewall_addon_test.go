@@ -0,0 +1,75 @@
+package contabo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestInstancesWithoutFirewallAddOn(t *testing.T) {
+	instancesWithAddOn := map[int]bool{1: true, 3: true}
+	cases := []struct {
+		name             string
+		old              []interface{}
+		new              []interface{}
+		addOnInstanceIds []interface{}
+		instanceIds      []int
+		checked          []int
+	}{
+		{name: "no added instances", old: []interface{}{1, 2}, new: []interface{}{1, 2}, instanceIds: nil, checked: nil},
+		{name: "removed instances", old: []interface{}{1, 2}, new: []interface{}{1}, instanceIds: nil, checked: nil},
+		{name: "added instance with add-on", old: []interface{}{}, new: []interface{}{1}, instanceIds: nil, checked: []int{1}},
+		{name: "added instance without add-on", old: []interface{}{1}, new: []interface{}{1, 2}, instanceIds: []int{2}, checked: []int{2}},
+		{name: "mixed", old: []interface{}{}, new: []interface{}{4, 3, 2, 1}, instanceIds: []int{2, 4}, checked: []int{1, 2, 3, 4}},
+		{name: "already upgraded", old: []interface{}{}, new: []interface{}{2, 4}, addOnInstanceIds: []interface{}{2}, instanceIds: []int{4}, checked: []int{4}},
+		{name: "kept instance without add-on", old: []interface{}{2}, new: []interface{}{2, 3}, instanceIds: nil, checked: []int{3}},
+	}
+	for _, c := range cases {
+		var checked []int
+		instanceIds, err := instancesWithoutFirewallAddOn(
+			schema.NewSet(schema.HashInt, c.old),
+			schema.NewSet(schema.HashInt, c.new),
+			schema.NewSet(schema.HashInt, c.addOnInstanceIds),
+			func(instanceId int) (bool, error) {
+				checked = append(checked, instanceId)
+				return instancesWithAddOn[instanceId], nil
+			},
+		)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.name, err)
+		}
+		if !reflect.DeepEqual(instanceIds, c.instanceIds) {
+			t.Errorf("%s: instancesWithoutFirewallAddOn() = %v, want %v", c.name, instanceIds, c.instanceIds)
+		}
+		if !reflect.DeepEqual(checked, c.checked) {
+			t.Errorf("%s: checked the add-ons of %v, want %v", c.name, checked, c.checked)
+		}
+	}
+
+	_, err := instancesWithoutFirewallAddOn(
+		schema.NewSet(schema.HashInt, []interface{}{}),
+		schema.NewSet(schema.HashInt, []interface{}{1}),
+		schema.NewSet(schema.HashInt, []interface{}{}),
+		func(instanceId int) (bool, error) {
+			return false, errors.New("not found")
+		},
+	)
+	if err == nil {
+		t.Error("instancesWithoutFirewallAddOn() succeeded although the add-ons could not be checked")
+	}
+}
+
+func TestFirewallAddOnWarning(t *testing.T) {
+	if diags := firewallAddOnWarning(nil); len(diags) != 0 {
+		t.Errorf("firewallAddOnWarning(nil) = %v, want no diagnostics", diags)
+	}
+	diags := firewallAddOnWarning([]int{3, 1})
+	if len(diags) != 1 || diags.HasError() {
+		t.Fatalf("firewallAddOnWarning() = %v, want one warning", diags)
+	}
+	if diags[0].Detail != "The instances [1 3] did not have the firewall add-on and have been upgraded with it. The add-on is charged with the instances." {
+		t.Errorf("firewallAddOnWarning() detail = %q", diags[0].Detail)
+	}
+}
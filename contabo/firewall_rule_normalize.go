@@ -0,0 +1,151 @@
+package contabo
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// normalizeProtocol returns the protocol of a rule as sent to the API, an
+// empty protocol matches any protocol.
+func normalizeProtocol(protocol string) string {
+	protocol = strings.ToLower(strings.TrimSpace(protocol))
+	if protocol == "any" {
+		return ""
+	}
+	return protocol
+}
+
+// normalizePortRange returns a port or port range in its shortest form,
+// e.g. `22-22` becomes `22`. Values which are no port range are returned
+// unchanged.
+func normalizePortRange(portRange string) string {
+	portRange = strings.TrimSpace(portRange)
+	parts := strings.Split(portRange, "-")
+	if len(parts) > 2 {
+		return portRange
+	}
+	ports := make([]string, 0, len(parts))
+	for _, part := range parts {
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return portRange
+		}
+		ports = append(ports, strconv.Itoa(port))
+	}
+	if len(ports) == 2 && ports[0] == ports[1] {
+		return ports[0]
+	}
+	return strings.Join(ports, "-")
+}
+
+// normalizeCidr returns an address or network in CIDR notation, e.g.
+// `10.0.0.1` becomes `10.0.0.1/32`. Values which are no address are
+// returned unchanged.
+func normalizeCidr(cidr string) string {
+	cidr = strings.TrimSpace(cidr)
+	if ip := net.ParseIP(cidr); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32"
+		}
+		return ip.String() + "/128"
+	}
+	if _, network, err := net.ParseCIDR(cidr); err == nil {
+		return network.String()
+	}
+	return cidr
+}
+
+func normalizeAll(values []string, normalize func(string) string) []string {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		normalized = append(normalized, normalize(value))
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// firewallRuleKey identifies a rule within a direction of a firewall by
+// the traffic it matches. Action and status can be changed in place.
+func firewallRuleKey(protocol string, destPorts []string, ipv4 []string, ipv6 []string) string {
+	return strings.Join([]string{
+		normalizeProtocol(protocol),
+		strings.Join(normalizeAll(destPorts, normalizePortRange), ","),
+		strings.Join(normalizeAll(ipv4, normalizeCidr), ","),
+		strings.Join(normalizeAll(ipv6, normalizeCidr), ","),
+	}, "|")
+}
+
+// hashFirewallRule hashes an inbound or outbound rule of contabo_firewall by
+// its normalized content, so equivalent rules are equal regardless of their
+// order and notation.
+func hashFirewallRule(v interface{}) int {
+	rule := v.(map[string]interface{})
+
+	ipv4, ipv6 := srcCidrsOf(rule)
+
+	action, status := firewallRuleActionAndStatus(rule)
+	protocol, _ := rule["protocol"].(string)
+
+	key := firewallRuleKey(protocol, stringsOf(rule["dest_ports"]), ipv4, ipv6)
+	return schema.HashString(key + "|" + strings.ToLower(action) + "|" + strings.ToLower(status))
+}
+
+func hashPortRange(v interface{}) int {
+	return schema.HashString(normalizePortRange(v.(string)))
+}
+
+func hashCidr(v interface{}) int {
+	return schema.HashString(normalizeCidr(v.(string)))
+}
+
+func suppressEquivalentProtocol(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeProtocol(old) == normalizeProtocol(new)
+}
+
+func suppressEquivalentPortRange(k, old, new string, d *schema.ResourceData) bool {
+	return normalizePortRange(old) == normalizePortRange(new)
+}
+
+func suppressEquivalentCidr(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeCidr(old) == normalizeCidr(new)
+}
+
+// srcCidrsOf returns the IPv4 and IPv6 networks of a rule.
+func srcCidrsOf(rule map[string]interface{}) ([]string, []string) {
+	var ipv4, ipv6 []string
+	if srcCidrs, ok := rule["src_cidr"].([]interface{}); ok {
+		for _, srcCidr := range srcCidrs {
+			srcCidrMap, ok := srcCidr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ipv4 = append(ipv4, stringsOf(srcCidrMap["ipv4"])...)
+			ipv6 = append(ipv6, stringsOf(srcCidrMap["ipv6"])...)
+		}
+	}
+	return ipv4, ipv6
+}
+
+// stringsOf returns the strings of a set or list value.
+func stringsOf(v interface{}) []string {
+	var values []interface{}
+	switch t := v.(type) {
+	case *schema.Set:
+		values = t.List()
+	case []interface{}:
+		values = t
+	case []string:
+		return t
+	}
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
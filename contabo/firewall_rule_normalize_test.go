@@ -0,0 +1,98 @@
+package contabo
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestNormalizePortRange(t *testing.T) {
+	cases := map[string]string{
+		"22":         "22",
+		"22-22":      "22",
+		" 22 - 22 ":  "22",
+		"8000-8080":  "8000-8080",
+		"080":        "80",
+		"1-65535":    "1-65535",
+		"http":       "http",
+		"1-2-3":      "1-2-3",
+		"":           "",
+		"0443-00443": "443",
+	}
+	for portRange, want := range cases {
+		if got := normalizePortRange(portRange); got != want {
+			t.Errorf("normalizePortRange(%q) = %q, want %q", portRange, got, want)
+		}
+	}
+}
+
+func TestNormalizeCidr(t *testing.T) {
+	cases := map[string]string{
+		"10.0.0.1":                  "10.0.0.1/32",
+		"10.0.0.1/32":               "10.0.0.1/32",
+		"10.0.0.0/8":                "10.0.0.0/8",
+		"10.1.2.3/8":                "10.0.0.0/8",
+		"0.0.0.0/0":                 "0.0.0.0/0",
+		"2001:db8::1":               "2001:db8::1/128",
+		"2001:0db8:0000::0001/128":  "2001:db8::1/128",
+		"2001:0db8:85a3::/48":       "2001:db8:85a3::/48",
+		"not an address":            "not an address",
+		" 194.165.134.20 ":          "194.165.134.20/32",
+		"2001:db8:85a3::8a2e:370:1": "2001:db8:85a3::8a2e:370:1/128",
+	}
+	for cidr, want := range cases {
+		if got := normalizeCidr(cidr); got != want {
+			t.Errorf("normalizeCidr(%q) = %q, want %q", cidr, got, want)
+		}
+	}
+}
+
+func TestHashFirewallRule(t *testing.T) {
+	rule := func(protocol string, destPorts []interface{}, ipv4 []interface{}, action string, status string) map[string]interface{} {
+		return map[string]interface{}{
+			"protocol":   protocol,
+			"action":     action,
+			"status":     status,
+			"dest_ports": schema.NewSet(hashPortRange, destPorts),
+			"src_cidr": []interface{}{
+				map[string]interface{}{
+					"ipv4": schema.NewSet(hashCidr, ipv4),
+					"ipv6": schema.NewSet(hashCidr, []interface{}{}),
+				},
+			},
+		}
+	}
+
+	base := rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active")
+	equivalent := map[string]map[string]interface{}{
+		"port order":     rule("tcp", []interface{}{"80", "22"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active"),
+		"port range":     rule("tcp", []interface{}{"22-22", "80-80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active"),
+		"address prefix": rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1/32", "192.168.0.0/16"}, "accept", "active"),
+		"protocol case":  rule("TCP", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active"),
+		"default action": rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "", "active"),
+		"default status": rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", ""),
+	}
+	for name, other := range equivalent {
+		if hashFirewallRule(other) != hashFirewallRule(base) {
+			t.Errorf("%s: equivalent rules have different hashes", name)
+		}
+	}
+
+	different := map[string]map[string]interface{}{
+		"port":     rule("tcp", []interface{}{"22", "443"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active"),
+		"protocol": rule("udp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "active"),
+		"address":  rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.2", "192.168.0.0/16"}, "accept", "active"),
+		"status":   rule("tcp", []interface{}{"22", "80"}, []interface{}{"10.0.0.1", "192.168.0.0/16"}, "accept", "inactive"),
+	}
+	for name, other := range different {
+		if hashFirewallRule(other) == hashFirewallRule(base) {
+			t.Errorf("%s: different rules have the same hash", name)
+		}
+	}
+}
+
+func TestFirewallRuleKeyAnyProtocol(t *testing.T) {
+	if firewallRuleKey("any", nil, nil, nil) != firewallRuleKey("", nil, nil, nil) {
+		t.Error("protocol any and an empty protocol should have the same key")
+	}
+}
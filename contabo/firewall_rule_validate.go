@@ -0,0 +1,270 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// firewallMaxRules is the maximum number of rules the API accepts per
+// direction of a firewall.
+const firewallMaxRules = 50
+
+var validateFirewallProtocol = validation.StringInSlice([]string{"tcp", "udp", "icmp", "any"}, true)
+
+var validateFirewallAction = validation.StringInSlice([]string{"accept"}, false)
+
+var validateFirewallRuleStatus = validation.StringInSlice([]string{"active", "inactive"}, false)
+
+// validatePortRange accepts a port or a port range like `8000-8080`.
+func validatePortRange(v interface{}, k string) (warnings []string, errors []error) {
+	if _, _, err := parsePortRange(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q %s", k, err))
+	}
+	return warnings, errors
+}
+
+// validateIpv4Cidr accepts an IPv4 address or network.
+func validateIpv4Cidr(v interface{}, k string) (warnings []string, errors []error) {
+	if ip := parseCidrIp(v.(string)); ip == nil || ip.To4() == nil {
+		errors = append(errors, fmt.Errorf("%q must be an IPv4 address or network in CIDR notation, got: %s", k, v))
+	}
+	return warnings, errors
+}
+
+// validateIpv6Cidr accepts an IPv6 address or network.
+func validateIpv6Cidr(v interface{}, k string) (warnings []string, errors []error) {
+	if ip := parseCidrIp(v.(string)); ip == nil || ip.To4() != nil {
+		errors = append(errors, fmt.Errorf("%q must be an IPv6 address or network in CIDR notation, got: %s", k, v))
+	}
+	return warnings, errors
+}
+
+func parsePortRange(portRange string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(portRange), "-")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("must be a port or a port range like 8000-8080, got: %s", portRange)
+	}
+	ports := make([]int, 0, len(parts))
+	for _, part := range parts {
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("must be a port or a port range like 8000-8080, got: %s", portRange)
+		} else if port < 1 || port > 65535 {
+			return 0, 0, fmt.Errorf("must only contain ports between 1 and 65535, got: %s", portRange)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	} else if ports[0] > ports[1] {
+		return 0, 0, fmt.Errorf("must start with the lower port, got: %s", portRange)
+	}
+	return ports[0], ports[1], nil
+}
+
+func parseCidrIp(cidr string) net.IP {
+	cidr = strings.TrimSpace(cidr)
+	if ip := net.ParseIP(cidr); ip != nil {
+		return ip
+	}
+	if ip, _, err := net.ParseCIDR(cidr); err == nil {
+		return ip
+	}
+	return nil
+}
+
+// checkFirewallRulePorts rejects destination ports for protocols without
+// ports.
+func checkFirewallRulePorts(protocol string, destPorts []string) error {
+	protocol = normalizeProtocol(protocol)
+	if len(destPorts) == 0 || protocol == "tcp" || protocol == "udp" {
+		return nil
+	}
+	if protocol == "" {
+		protocol = "any"
+	}
+	return fmt.Errorf("rules with protocol %s cannot have dest_ports, only tcp and udp rules can", protocol)
+}
+
+// checkFirewallRules checks the rules of one direction of a firewall for
+// protocols with ports, duplicates and the maximum number of rules. Active
+// rules with the same action, protocol, ports and sources are duplicates,
+// inactive rules are kept as toggles and never conflict.
+func checkFirewallRules(direction string, rules []interface{}) error {
+	if len(rules) > firewallMaxRules {
+		return fmt.Errorf("%s rules: a firewall can have at most %d rules per direction, got %d", direction, firewallMaxRules, len(rules))
+	}
+
+	keys := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		ruleMap := rule.(map[string]interface{})
+		protocol, _ := ruleMap["protocol"].(string)
+		destPorts := stringsOf(ruleMap["dest_ports"])
+		if err := checkFirewallRulePorts(protocol, destPorts); err != nil {
+			return fmt.Errorf("%s rules: %s", direction, err)
+		}
+
+		traffic := firewallRuleTrafficOf(ruleMap)
+		if !traffic.active {
+			continue
+		}
+		key := traffic.key()
+		if keys[key] {
+			return fmt.Errorf("%s rules: several active rules match the same traffic (%s), merge them into one rule", direction, traffic)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+// firewallRuleOverlapWarnings warns about active rules of one direction with
+// the same action whose traffic overlaps without being the same, e.g. the
+// ports `80-90` and `85`. Such rules are valid, but often meant to be merged.
+func firewallRuleOverlapWarnings(direction string, rules []interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	traffics := make([]firewallRuleTraffic, 0, len(rules))
+	for _, rule := range rules {
+		traffic := firewallRuleTrafficOf(rule.(map[string]interface{}))
+		if !traffic.active {
+			continue
+		}
+		for _, other := range traffics {
+			if traffic.action == other.action && traffic.key() != other.key() && traffic.overlaps(other) {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "Overlapping firewall rules",
+					Detail:   fmt.Sprintf("The %s rules (%s) and (%s) match overlapping traffic, consider merging them.", direction, other, traffic),
+				})
+			}
+		}
+		traffics = append(traffics, traffic)
+	}
+	return diags
+}
+
+// firewallRuleTraffic is the traffic matched by a rule. An empty protocol,
+// no ports or no source networks match any protocol, port or source.
+type firewallRuleTraffic struct {
+	protocol  string
+	destPorts []string
+	ipv4      []string
+	ipv6      []string
+	action    string
+	active    bool
+}
+
+func firewallRuleTrafficOf(ruleMap map[string]interface{}) firewallRuleTraffic {
+	protocol, _ := ruleMap["protocol"].(string)
+	ipv4, ipv6 := srcCidrsOf(ruleMap)
+	action, status := firewallRuleActionAndStatus(ruleMap)
+	return firewallRuleTraffic{
+		protocol:  normalizeProtocol(protocol),
+		destPorts: normalizeAll(stringsOf(ruleMap["dest_ports"]), normalizePortRange),
+		ipv4:      normalizeAll(ipv4, normalizeCidr),
+		ipv6:      normalizeAll(ipv6, normalizeCidr),
+		action:    strings.ToLower(action),
+		active:    strings.EqualFold(status, "active"),
+	}
+}
+
+// key identifies the traffic and action of a rule regardless of its notation.
+func (traffic firewallRuleTraffic) key() string {
+	return firewallRuleKey(traffic.protocol, traffic.destPorts, traffic.ipv4, traffic.ipv6) + "|" + traffic.action
+}
+
+func (traffic firewallRuleTraffic) String() string {
+	protocol := traffic.protocol
+	if protocol == "" {
+		protocol = "any"
+	}
+	return fmt.Sprintf("protocol %s, dest_ports %v, src_cidr %v %v", protocol, traffic.destPorts, traffic.ipv4, traffic.ipv6)
+}
+
+// overlaps tells whether some traffic is matched by both rules.
+func (traffic firewallRuleTraffic) overlaps(other firewallRuleTraffic) bool {
+	if traffic.protocol != "" && other.protocol != "" && traffic.protocol != other.protocol {
+		return false
+	}
+	return portRangesOverlap(traffic.destPorts, other.destPorts) && sourcesOverlap(traffic, other)
+}
+
+func portRangesOverlap(portRanges []string, otherPortRanges []string) bool {
+	if len(portRanges) == 0 || len(otherPortRanges) == 0 {
+		return true
+	}
+	for _, portRange := range portRanges {
+		from, to, err := parsePortRange(portRange)
+		if err != nil {
+			continue
+		}
+		for _, otherPortRange := range otherPortRanges {
+			otherFrom, otherTo, err := parsePortRange(otherPortRange)
+			if err == nil && from <= otherTo && otherFrom <= to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sourcesOverlap(traffic firewallRuleTraffic, other firewallRuleTraffic) bool {
+	if len(traffic.ipv4)+len(traffic.ipv6) == 0 || len(other.ipv4)+len(other.ipv6) == 0 {
+		return true
+	}
+	return networksOverlap(traffic.ipv4, other.ipv4) || networksOverlap(traffic.ipv6, other.ipv6)
+}
+
+func networksOverlap(cidrs []string, otherCidrs []string) bool {
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(normalizeCidr(cidr))
+		if err != nil {
+			continue
+		}
+		for _, otherCidr := range otherCidrs {
+			_, otherNetwork, err := net.ParseCIDR(normalizeCidr(otherCidr))
+			if err == nil && (network.Contains(otherNetwork.IP) || otherNetwork.Contains(network.IP)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateFirewallRulesDiff validates the rules of a firewall as a whole,
+// before anything is changed.
+func validateFirewallRulesDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("rules") || !d.NewValueKnown("rules") {
+		return nil
+	}
+	rules := d.Get("rules").([]interface{})
+	if len(rules) == 0 || rules[0] == nil {
+		return nil
+	}
+	rulesMap := rules[0].(map[string]interface{})
+	for _, direction := range []string{inboundRuleKey, outboundRuleKey} {
+		directionRules, ok := rulesMap[direction].(*schema.Set)
+		if !ok {
+			continue
+		}
+		if err := checkFirewallRules(direction, directionRules.List()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceFirewallRuleCustomizeDiff rejects destination ports for protocols
+// without ports.
+func resourceFirewallRuleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("protocol") || !d.NewValueKnown("dest_ports") {
+		return nil
+	}
+	return checkFirewallRulePorts(d.Get("protocol").(string), stringsOf(d.Get("dest_ports")))
+}
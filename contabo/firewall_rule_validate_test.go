@@ -0,0 +1,166 @@
+package contabo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestValidatePortRange(t *testing.T) {
+	cases := map[string]bool{
+		"22":        true,
+		"8000-8080": true,
+		"1-65535":   true,
+		"22-22":     true,
+		"0":         false,
+		"70000":     false,
+		"8080-8000": false,
+		"1-2-3":     false,
+		"http":      false,
+		"":          false,
+	}
+	for portRange, valid := range cases {
+		_, errors := validatePortRange(portRange, "dest_ports")
+		if valid != (len(errors) == 0) {
+			t.Errorf("validatePortRange(%q): valid = %t, errors %v", portRange, valid, errors)
+		}
+	}
+}
+
+func TestValidateCidr(t *testing.T) {
+	cases := []struct {
+		cidr string
+		ipv4 bool
+		ipv6 bool
+	}{
+		{"10.0.0.1", true, false},
+		{"10.0.0.0/8", true, false},
+		{"0.0.0.0/0", true, false},
+		{"2001:db8::1", false, true},
+		{"2001:db8::/32", false, true},
+		{"10.0.0.0/33", false, false},
+		{"10.0.0", false, false},
+		{"2001:db8::/129", false, false},
+		{"not an address", false, false},
+	}
+	for _, c := range cases {
+		if _, errors := validateIpv4Cidr(c.cidr, "ipv4"); c.ipv4 != (len(errors) == 0) {
+			t.Errorf("validateIpv4Cidr(%q): valid = %t, errors %v", c.cidr, c.ipv4, errors)
+		}
+		if _, errors := validateIpv6Cidr(c.cidr, "ipv6"); c.ipv6 != (len(errors) == 0) {
+			t.Errorf("validateIpv6Cidr(%q): valid = %t, errors %v", c.cidr, c.ipv6, errors)
+		}
+	}
+}
+
+func testFirewallRule(protocol string, destPorts []interface{}, ipv4 []interface{}, status string) interface{} {
+	return map[string]interface{}{
+		"protocol":   protocol,
+		"action":     "accept",
+		"status":     status,
+		"dest_ports": schema.NewSet(hashPortRange, destPorts),
+		"src_cidr": []interface{}{
+			map[string]interface{}{
+				"ipv4": schema.NewSet(hashCidr, ipv4),
+				"ipv6": schema.NewSet(hashCidr, []interface{}{}),
+			},
+		},
+	}
+}
+
+func testIpv6FirewallRule(protocol string, destPorts []interface{}, ipv6 []interface{}) interface{} {
+	return map[string]interface{}{
+		"protocol":   protocol,
+		"action":     "accept",
+		"status":     "active",
+		"dest_ports": schema.NewSet(hashPortRange, destPorts),
+		"src_cidr": []interface{}{
+			map[string]interface{}{
+				"ipv4": schema.NewSet(hashCidr, []interface{}{}),
+				"ipv6": schema.NewSet(hashCidr, ipv6),
+			},
+		},
+	}
+}
+
+// testOverlappingFirewallRules are valid rules which overlap without being
+// the same.
+func testOverlappingFirewallRules() map[string][]interface{} {
+	rule := testFirewallRule
+	ssh := rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.1"}, "active")
+	return map[string][]interface{}{
+		"office and https": {rule("any", nil, []interface{}{"203.0.113.7/32"}, "active"), rule("tcp", []interface{}{"443"}, []interface{}{"0.0.0.0/0"}, "active")},
+		"port in range":    {rule("tcp", []interface{}{"80-90"}, nil, "active"), rule("tcp", []interface{}{"85"}, nil, "active")},
+		"ranges overlap":   {rule("udp", []interface{}{"1000-2000"}, nil, "active"), rule("udp", []interface{}{"3000", "1500-2500"}, nil, "active")},
+		"all ports":        {ssh, rule("tcp", nil, []interface{}{"10.0.0.1"}, "active")},
+		"any protocol":     {ssh, rule("any", nil, []interface{}{"10.0.0.1"}, "active")},
+		"host in network":  {ssh, rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.0/8"}, "active")},
+		"any source":       {ssh, rule("tcp", []interface{}{"20-25"}, nil, "active")},
+		"ipv6 host in /64": {testIpv6FirewallRule("tcp", []interface{}{"22"}, []interface{}{"2001:db8::1"}), testIpv6FirewallRule("tcp", []interface{}{"22"}, []interface{}{"2001:db8::/64"})},
+	}
+}
+
+func TestCheckFirewallRules(t *testing.T) {
+	rule := testFirewallRule
+	ssh := rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.1"}, "active")
+
+	valid := map[string][]interface{}{
+		"no rules":         {},
+		"tcp and udp":      {ssh, rule("udp", []interface{}{"51820"}, []interface{}{"10.0.0.1"}, "active")},
+		"icmp":             {rule("icmp", nil, []interface{}{"0.0.0.0/0"}, "active")},
+		"other network":    {ssh, rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.2"}, "active")},
+		"other ports":      {rule("tcp", []interface{}{"80-90"}, nil, "active"), rule("tcp", []interface{}{"91", "443"}, nil, "active")},
+		"ipv4 and ipv6":    {rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.0/8"}, "active"), testIpv6FirewallRule("tcp", []interface{}{"22"}, []interface{}{"2001:db8::/32"})},
+		"other subnets":    {rule("tcp", []interface{}{"22"}, []interface{}{"10.0.0.0/24"}, "active"), rule("tcp", []interface{}{"22"}, []interface{}{"10.0.1.0/24"}, "active")},
+		"inactive toggle":  {ssh, rule("tcp", []interface{}{"22-22"}, []interface{}{"10.0.0.1/32"}, "inactive")},
+		"inactive toggles": {rule("tcp", []interface{}{"22"}, nil, "inactive"), rule("tcp", []interface{}{"22"}, nil, "inactive")},
+	}
+	for name, rules := range testOverlappingFirewallRules() {
+		valid["overlapping "+name] = rules
+	}
+	for name, rules := range valid {
+		if err := checkFirewallRules(inboundRuleKey, rules); err != nil {
+			t.Errorf("%s: unexpected error %s", name, err)
+		}
+	}
+
+	tooMany := make([]interface{}, 0, firewallMaxRules+1)
+	for i := 0; i <= firewallMaxRules; i++ {
+		tooMany = append(tooMany, rule("tcp", []interface{}{fmt.Sprint(i + 1)}, []interface{}{"10.0.0.1"}, "active"))
+	}
+	invalid := map[string][]interface{}{
+		"icmp with ports":    {rule("icmp", []interface{}{"22"}, []interface{}{"10.0.0.1"}, "active")},
+		"any with ports":     {rule("any", []interface{}{"22"}, []interface{}{"10.0.0.1"}, "active")},
+		"duplicate":          {ssh, rule("tcp", []interface{}{"22-22"}, []interface{}{"10.0.0.1/32"}, "active")},
+		"duplicate protocol": {ssh, rule("TCP", []interface{}{"22"}, []interface{}{"10.0.0.1"}, "active")},
+		"duplicate any":      {rule("any", nil, nil, "active"), rule("", nil, nil, "active")},
+		"too many rules":     tooMany,
+	}
+	for name, rules := range invalid {
+		if err := checkFirewallRules(inboundRuleKey, rules); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFirewallRuleOverlapWarnings(t *testing.T) {
+	for name, rules := range testOverlappingFirewallRules() {
+		if diags := firewallRuleOverlapWarnings(inboundRuleKey, rules); len(diags) != 1 || diags.HasError() {
+			t.Errorf("%s: firewallRuleOverlapWarnings() = %v, want one warning", name, diags)
+		}
+	}
+
+	rule := testFirewallRule
+	quiet := map[string][]interface{}{
+		"other ports":     {rule("tcp", []interface{}{"80-90"}, nil, "active"), rule("tcp", []interface{}{"91"}, nil, "active")},
+		"other protocols": {rule("tcp", []interface{}{"53"}, nil, "active"), rule("udp", []interface{}{"53"}, nil, "active")},
+		"inactive":        {rule("tcp", []interface{}{"80-90"}, nil, "active"), rule("tcp", []interface{}{"85"}, nil, "inactive")},
+		"duplicate":       {rule("tcp", []interface{}{"22"}, nil, "active"), rule("tcp", []interface{}{"22-22"}, nil, "active")},
+	}
+	for name, rules := range quiet {
+		if diags := firewallRuleOverlapWarnings(inboundRuleKey, rules); len(diags) != 0 {
+			t.Errorf("%s: firewallRuleOverlapWarnings() = %v, want no warnings", name, diags)
+		}
+	}
+}
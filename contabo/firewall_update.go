@@ -0,0 +1,190 @@
+package contabo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	uuid "github.com/satori/go.uuid"
+)
+
+// firewallConflictTimeout is how long conflicting firewall updates are
+// retried.
+const firewallConflictTimeout = 5 * time.Minute
+
+// firewallLocks holds a mutex per firewall id. Resources changing the same
+// firewall take it, so their read-modify-write cycles do not interleave.
+// The locks only cover this provider process, changes of other processes
+// are detected by reading the firewall again after writing it.
+var firewallLocks sync.Map
+
+// errFirewallChangeLost is returned by firewall updates whose change is
+// missing when the firewall is read again. The update is retried.
+var errFirewallChangeLost = errors.New("the change has been overwritten concurrently")
+
+// errFirewallNotFound is returned by firewall updates if the firewall does
+// not exist (anymore).
+var errFirewallNotFound = errors.New("the firewall does not exist")
+
+// lockFirewall locks a firewall and returns the function unlocking it.
+func lockFirewall(firewallId string) func() {
+	lock, _ := firewallLocks.LoadOrStore(firewallId, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+func isConflict(httpResp *http.Response) bool {
+	return httpResp != nil && httpResp.StatusCode == http.StatusConflict
+}
+
+func isNotFound(httpResp *http.Response) bool {
+	return httpResp != nil && httpResp.StatusCode == http.StatusNotFound
+}
+
+func isForbidden(httpResp *http.Response) bool {
+	return httpResp != nil && httpResp.StatusCode == http.StatusForbidden
+}
+
+func isBadRequest(httpResp *http.Response) bool {
+	return httpResp != nil && httpResp.StatusCode == http.StatusBadRequest
+}
+
+// isTemporaryError reports whether a request failed because the API is
+// overloaded or temporarily unavailable.
+func isTemporaryError(httpResp *http.Response) bool {
+	return httpResp != nil && (httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= http.StatusInternalServerError)
+}
+
+// updateFirewallRules reads the current rules of a firewall, lets modify
+// change them and writes them back. The API has no precondition for writing
+// the rules, so they are read again and the cycle is repeated until applied
+// finds the change. A change of another client between reading and writing
+// the rules is still overwritten, unless that client checks its change the
+// same way. It returns errFirewallNotFound if the firewall does not exist.
+func updateFirewallRules(
+	ctx context.Context,
+	client *openapi.APIClient,
+	firewallId string,
+	modify func(rules *openapi.RulesRequest) error,
+	applied func(rules *openapi.RulesRequest) bool,
+) error {
+	defer lockFirewall(firewallId)()
+
+	return resource.RetryContext(ctx, firewallConflictTimeout, func() *resource.RetryError {
+		rules, retryErr := retrieveFirewallRules(ctx, client, firewallId)
+		if retryErr != nil {
+			return retryErr
+		}
+		if err := modify(rules); err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		putFirewallRequest := *openapi.NewPutFirewallRequestWithDefaults()
+		putFirewallRequest.Rules = rules
+		_, httpResp, err := client.FirewallsApi.
+			PutFirewall(ctx, firewallId).
+			XRequestId(uuid.NewV4().String()).
+			PutFirewallRequest(putFirewallRequest).
+			Execute()
+		if isConflict(httpResp) {
+			return resource.RetryableError(fmt.Errorf("firewall %s has been changed concurrently", firewallId))
+		} else if isNotFound(httpResp) {
+			return resource.NonRetryableError(fmt.Errorf("firewall %s: %w", firewallId, errFirewallNotFound))
+		} else if err != nil {
+			return resource.NonRetryableError(DiagnosticsToError(HandleResponseErrors(nil, httpResp)))
+		}
+
+		rules, retryErr = retrieveFirewallRules(ctx, client, firewallId)
+		if retryErr != nil {
+			return retryErr
+		}
+		if !applied(rules) {
+			return resource.RetryableError(fmt.Errorf("firewall %s: %w", firewallId, errFirewallChangeLost))
+		}
+		return nil
+	})
+}
+
+// retrieveFirewallRules returns the current rules of a firewall in their
+// request representation.
+func retrieveFirewallRules(ctx context.Context, client *openapi.APIClient, firewallId string) (*openapi.RulesRequest, *resource.RetryError) {
+	res, httpResp, err := client.FirewallsApi.
+		RetrieveFirewall(ctx, firewallId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if isNotFound(httpResp) {
+		return nil, resource.NonRetryableError(fmt.Errorf("firewall %s: %w", firewallId, errFirewallNotFound))
+	} else if err != nil {
+		return nil, resource.NonRetryableError(DiagnosticsToError(HandleResponseErrors(nil, httpResp)))
+	} else if len(res.Data) != 1 {
+		return nil, resource.NonRetryableError(DiagnosticsToError(MultipleDataObjectsError(nil)))
+	}
+
+	rules := openapi.NewRulesRequestWithDefaults()
+	rules.Inbound = firewallRuleRequestsFromResponse(res.Data[0].Rules.Inbound)
+	rules.Outbound = firewallRuleRequestsFromResponse(res.Data[0].Rules.Outbound)
+	return rules, nil
+}
+
+// retryFirewallConflicts locks a firewall and calls update until the
+// firewall is no longer changed concurrently and the API is available.
+// update returns the response of its API call, or errFirewallChangeLost if
+// it has read the firewall again and its change is missing.
+func retryFirewallConflicts(
+	ctx context.Context,
+	firewallId string,
+	update func() (*http.Response, error),
+) diag.Diagnostics {
+	defer lockFirewall(firewallId)()
+	return retryOnFirewallConflict(ctx, firewallId, update)
+}
+
+// retryOnFirewallConflict is retryFirewallConflicts for callers which
+// already hold the lock of the firewall.
+func retryOnFirewallConflict(
+	ctx context.Context,
+	firewallId string,
+	update func() (*http.Response, error),
+) diag.Diagnostics {
+	err := resource.RetryContext(ctx, firewallConflictTimeout, func() *resource.RetryError {
+		httpResp, err := update()
+		if errors.Is(err, errFirewallChangeLost) {
+			return resource.RetryableError(fmt.Errorf("firewall %s: %w", firewallId, err))
+		} else if isConflict(httpResp) {
+			return resource.RetryableError(fmt.Errorf("firewall %s has been changed concurrently", firewallId))
+		} else if isTemporaryError(httpResp) {
+			return resource.RetryableError(fmt.Errorf("firewall %s is temporarily unavailable: %s", firewallId, httpResp.Status))
+		} else if err != nil && httpResp != nil && httpResp.StatusCode < http.StatusBadRequest {
+			// the request succeeded, but its response is unexpected
+			return resource.NonRetryableError(err)
+		} else if err != nil {
+			return resource.NonRetryableError(DiagnosticsToError(HandleResponseErrors(nil, httpResp)))
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// firewallRuleRequestsFromResponse converts the rules of a firewall back to
+// their request representation, so they can be written again.
+func firewallRuleRequestsFromResponse(rulesResponse []openapi.FirewallRuleResponse) []openapi.FirewallRuleRequest {
+	ruleRequests := make([]openapi.FirewallRuleRequest, 0, len(rulesResponse))
+	for _, rule := range rulesResponse {
+		srcCidr := *openapi.NewSrcCidrWithDefaults()
+		srcCidr.SetIpv4(rule.SrcCidr.GetIpv4())
+		srcCidr.SetIpv6(rule.SrcCidr.GetIpv6())
+		ruleRequest := openapi.NewFirewallRuleRequest(rule.Protocol, rule.DestPorts, srcCidr, rule.Action, rule.Status)
+		ruleRequests = append(ruleRequests, *ruleRequest)
+	}
+	return ruleRequests
+}
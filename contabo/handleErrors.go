@@ -2,6 +2,7 @@ package contabo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -94,3 +95,14 @@ func HandleDownloadErrors(
 		Detail:   "Download error, check the url availability and retry",
 	})
 }
+
+func DiagnosticsToError(
+	diags diag.Diagnostics,
+) error {
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			return fmt.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+	return errors.New("unexpected API error")
+}
@@ -15,13 +15,21 @@ func TestContaboFirewallImport(t *testing.T) {
 		CheckDestroy: testAccCheckFirewallDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config:             testCheckContaboFirewallConfigImport(),
-				ExpectNonEmptyPlan: true,
+				Config: testCheckContaboFirewallConfigImport(),
 			},
 			{
-				ResourceName:       resourceName,
-				ImportState:        true,
-				ExpectNonEmptyPlan: true,
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// which add-ons the provider has booked is not known on import
+				ImportStateVerifyIgnore: []string{"auto_enable_addon", "addon_instance_ids"},
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateId:           "terraform-firewall-import",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"auto_enable_addon", "addon_instance_ids"},
 			},
 		},
 	})
@@ -31,10 +39,16 @@ func testCheckContaboFirewallConfigImport() string {
 	return `
 		provider "contabo" {}
 
+		resource "contabo_instance" "import" {
+			display_name = "firewall import"
+		}
+
 		resource "contabo_firewall" "import" {
-		name        = "terraform-firewall-import"
-		description	= "terraform-description-import"
-		status 		= "active"
+		name              = "terraform-firewall-import"
+		description       = "terraform-description-import"
+		status            = "active"
+		instance_ids      = [contabo_instance.import.id]
+		auto_enable_addon = true
 		rules {
 			inbound {
 				protocol   = "tcp"
@@ -45,6 +59,14 @@ func testCheckContaboFirewallConfigImport() string {
 						ipv4 = ["194.165.134.20", "194.165.134.21"]
 					}
 				}
+			inbound {
+				protocol = "icmp"
+				action   = "accept"
+				status   = "active"
+				src_cidr {
+						ipv4 = ["0.0.0.0/0"]
+					}
+				}
 			}
 		}
 	`
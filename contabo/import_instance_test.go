@@ -0,0 +1,122 @@
+package contabo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const instanceImportResourceName = "contabo_instance.import"
+
+const instanceImportLicenseResourceName = "contabo_instance.import_license"
+
+var importInstanceDisplayName = (uuid.New()).String()
+var importInstanceLicenseDisplayName = (uuid.New()).String()
+
+func TestContaboInstanceImport(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboInstanceConfigImport(),
+			},
+			{
+				ResourceName:      instanceImportResourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// These arguments are only used during installation and are
+				// not returned by the API.
+				ImportStateVerifyIgnore: []string{"root_password", "root_password_wo", "user_data", "license", "period"},
+			},
+			{
+				Config:   testCheckContaboInstanceConfigImport(),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testCheckContaboInstanceConfigImport() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "import" {
+			display_name = "` + importInstanceDisplayName + `"
+			image_id     = "66abf39a-ba8b-425e-a385-8eb347ceac10"
+			region       = "EU"
+		}
+	`
+}
+
+// TestContaboInstanceImportLicense checks that setting the license of an
+// imported instance records it instead of replacing the instance. The API
+// does not return the license, the state of the instance created without a
+// license is the same as the state of an imported instance.
+func TestContaboInstanceImportLicense(t *testing.T) {
+	var instanceId string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboInstanceConfigImportLicense(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckInstanceId(instanceImportLicenseResourceName, &instanceId),
+				),
+			},
+			{
+				ResourceName:      instanceImportLicenseResourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// These arguments are only used during installation and are
+				// not returned by the API.
+				ImportStateVerifyIgnore: []string{"root_password", "root_password_wo", "user_data", "license", "period"},
+			},
+			{
+				Config: testCheckContaboInstanceConfigImportLicense("PleskHost"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(instanceImportLicenseResourceName, "license", "PleskHost"),
+					resource.TestCheckResourceAttrPtr(instanceImportLicenseResourceName, "id", &instanceId),
+				),
+			},
+			{
+				Config:   testCheckContaboInstanceConfigImportLicense("PleskHost"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccCheckInstanceId(resourceName string, instanceId *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found", resourceName)
+		}
+		*instanceId = rs.Primary.ID
+		return nil
+	}
+}
+
+func testCheckContaboInstanceConfigImportLicense(license string) string {
+	licenseArgument := ""
+	if license != "" {
+		licenseArgument = `license      = "` + license + `"`
+	}
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "import_license" {
+			display_name = "` + importInstanceLicenseDisplayName + `"
+			image_id     = "66abf39a-ba8b-425e-a385-8eb347ceac10"
+			region       = "EU"
+			` + licenseArgument + `
+		}
+	`
+}
@@ -0,0 +1,96 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	uuid "github.com/satori/go.uuid"
+)
+
+// listPageSize is the number of objects requested per page from list endpoints.
+const listPageSize = 100
+
+// retrieveAllInstances pages through the compute instances of the account.
+// filter can be used to add query parameters to every page request.
+func retrieveAllInstances(
+	ctx context.Context,
+	client *openapi.APIClient,
+	filter func(openapi.ApiRetrieveInstancesListRequest) openapi.ApiRetrieveInstancesListRequest,
+) ([]openapi.ListInstancesResponseData, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var instances []openapi.ListInstancesResponseData
+
+	for page := int64(1); ; page++ {
+		request := client.InstancesApi.
+			RetrieveInstancesList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize)
+		if filter != nil {
+			request = filter(request)
+		}
+
+		res, httpResp, err := request.Execute()
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		instances = append(instances, res.Data...)
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return instances, diags
+		}
+	}
+}
+
+// retrieveAllVips pages through the VIPs of the account.
+func retrieveAllVips(ctx context.Context, client *openapi.APIClient) ([]openapi.VipResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var vips []openapi.VipResponse
+
+	for page := int64(1); ; page++ {
+		res, httpResp, err := client.VIPApi.
+			RetrieveVipList(ctx).
+			XRequestId(uuid.NewV4().String()).
+			Page(page).
+			Size(listPageSize).
+			Execute()
+
+		if err != nil {
+			return nil, HandleResponseErrors(diags, httpResp)
+		}
+
+		vips = append(vips, res.Data...)
+		if len(res.Data) == 0 || page >= int64(res.Pagination.TotalPages) {
+			return vips, diags
+		}
+	}
+}
+
+func vipIp(vip openapi.VipResponse) string {
+	return vip.GetV4().Ip
+}
+
+// instanceOwnsIp reports whether ip is the primary IPv4 address, an
+// additional IPv4 address or part of one of the IPv6 networks of an instance.
+func instanceOwnsIp(ipConfig openapi.IpConfig2, additionalIps []openapi.AdditionalIp, ip net.IP) bool {
+	if ip.Equal(net.ParseIP(ipConfig.V4.Ip)) || ipv6NetworkContains(ipConfig.V6.Ip, int64(ipConfig.V6.NetmaskCidr), ip) {
+		return true
+	}
+	for _, additionalIp := range additionalIps {
+		if ipV4, ok := additionalIp.GetV4Ok(); ok && ip.Equal(net.ParseIP(ipV4.Ip)) {
+			return true
+		}
+		if ipV6, ok := additionalIp.GetV6Ok(); ok && ipv6NetworkContains(ipV6.Ip, int64(ipV6.NetmaskCidr), ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func ipv6NetworkContains(networkIp string, netmaskCidr int64, ip net.IP) bool {
+	_, network, err := net.ParseCIDR(fmt.Sprintf("%s/%d", networkIp, netmaskCidr))
+	return err == nil && network.Contains(ip)
+}
@@ -0,0 +1,179 @@
+package contabo
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	rootPasswordMinLength       = 8
+	rootPasswordMaxLength       = 30
+	generatedRootPasswordLength = 24
+)
+
+const (
+	rootPasswordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	rootPasswordLower   = "abcdefghijklmnopqrstuvwxyz"
+	rootPasswordDigits  = "0123456789"
+	rootPasswordSpecial = "!@#$^&*?_~"
+)
+
+func validateRootPassword(v interface{}, k string) (warnings []string, errors []error) {
+	password := v.(string)
+	if len(password) < rootPasswordMinLength || len(password) > rootPasswordMaxLength {
+		return warnings, append(errors, fmt.Errorf("%q must be between %d and %d characters long", k, rootPasswordMinLength, rootPasswordMaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, char := range password {
+		switch {
+		case strings.ContainsRune(rootPasswordUpper, char):
+			hasUpper = true
+		case strings.ContainsRune(rootPasswordLower, char):
+			hasLower = true
+		case strings.ContainsRune(rootPasswordDigits, char):
+			hasDigit = true
+		case !strings.ContainsRune(rootPasswordSpecial, char):
+			return warnings, append(errors, fmt.Errorf("%q contains %q, allowed special characters are %s", k, char, rootPasswordSpecial))
+		}
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		errors = append(errors, fmt.Errorf("%q must contain at least one upper case letter, one lower case letter and one digit", k))
+	}
+	return warnings, errors
+}
+
+// generateRootPassword returns a random password which contains at least one
+// character of every class the API requires.
+func generateRootPassword() (string, error) {
+	classes := []string{rootPasswordUpper, rootPasswordLower, rootPasswordDigits, rootPasswordSpecial}
+	alphabet := strings.Join(classes, "")
+
+	password := make([]byte, generatedRootPasswordLength)
+	for index := range password {
+		charset := alphabet
+		if index < len(classes) {
+			charset = classes[index]
+		}
+		char, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		password[index] = char
+	}
+
+	// move the mandatory characters to random positions
+	for index := len(password) - 1; index > 0; index-- {
+		swap, err := rand.Int(rand.Reader, big.NewInt(int64(index+1)))
+		if err != nil {
+			return "", err
+		}
+		password[index], password[swap.Int64()] = password[swap.Int64()], password[index]
+	}
+	return string(password), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+	return charset[index.Int64()], nil
+}
+
+// rootPasswordValue returns the plain root password which has to be set on
+// (re-)installation, or an empty string if root_password_wo is not set and
+// no password should be generated.
+func rootPasswordValue(d *schema.ResourceData) (string, error) {
+	if password := d.Get("root_password_wo").(string); password != "" {
+		return password, d.Set("generated_root_password", "")
+	}
+	if !d.Get("generate_root_password").(bool) {
+		return "", d.Set("generated_root_password", "")
+	}
+	if password := d.Get("generated_root_password").(string); password != "" {
+		return password, nil
+	}
+
+	password, err := generateRootPassword()
+	if err != nil {
+		return "", err
+	}
+	return password, d.Set("generated_root_password", password)
+}
+
+// createRootPasswordSecret stores the plain root password in a temporary
+// secret, as the API only accepts secret ids for the root password. It
+// returns nil if no plain root password is configured.
+func createRootPasswordSecret(
+	ctx context.Context,
+	client *openapi.APIClient,
+	d *schema.ResourceData,
+) (*int64, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	password, err := rootPasswordValue(d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if password == "" {
+		return nil, diags
+	}
+
+	createSecretRequest := openapi.NewCreateSecretRequestWithDefaults()
+	createSecretRequest.Name = "terraform-root-password-" + uuid.NewV4().String()
+	createSecretRequest.Value = password
+	createSecretRequest.Type = "password"
+
+	res, httpResp, err := client.SecretsApi.
+		CreateSecret(ctx).
+		XRequestId(uuid.NewV4().String()).
+		CreateSecretRequest(*createSecretRequest).
+		Execute()
+
+	if err != nil {
+		return nil, HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) != 1 {
+		return nil, MultipleDataObjectsError(diags)
+	}
+
+	secretId := res.Data[0].SecretId
+	return &secretId, diags
+}
+
+// deleteRootPasswordSecret removes the temporary secret once the instance is
+// installed or its installation has failed. It is removed even if the apply
+// has been interrupted. A failure does not fail the apply, the secret can be
+// removed manually.
+func deleteRootPasswordSecret(ctx context.Context, client *openapi.APIClient, secretId *int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if secretId == nil {
+		return diags
+	}
+
+	httpResp, err := client.SecretsApi.
+		DeleteSecret(context.WithoutCancel(ctx), *secretId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil {
+		status := "no response"
+		if httpResp != nil {
+			status = httpResp.Status
+		}
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Temporary root password secret could not be deleted",
+			Detail:   fmt.Sprintf("The secret %d holding the root password could not be deleted (%s), please delete it manually.", *secretId, status),
+		})
+	}
+	return diags
+}
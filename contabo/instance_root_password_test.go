@@ -0,0 +1,56 @@
+package contabo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRootPassword(t *testing.T) {
+	cases := map[string]bool{
+		"Secret123":                     true,
+		"Secret12":                      true,
+		"Secret123!@#$^&*?_~":           true,
+		"Aa1" + strings.Repeat("x", 27): true,
+		"Aa1" + strings.Repeat("x", 28): false,
+		"Secre1":                        false,
+		"":                              false,
+		"secret123":                     false,
+		"SECRET123":                     false,
+		"SecretPassword":                false,
+		"Secret 123":                    false,
+		"Secret123%":                    false,
+		"Secret123\"":                   false,
+		"Sécret123":                     false,
+	}
+	for password, valid := range cases {
+		_, errors := validateRootPassword(password, "root_password_wo")
+		if valid != (len(errors) == 0) {
+			t.Errorf("validateRootPassword(%q): valid = %t, errors %v", password, valid, errors)
+		}
+	}
+}
+
+func TestGenerateRootPassword(t *testing.T) {
+	passwords := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		password, err := generateRootPassword()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != generatedRootPasswordLength {
+			t.Errorf("generateRootPassword() = %q, want %d characters", password, generatedRootPasswordLength)
+		}
+		if _, errors := validateRootPassword(password, "generated_root_password"); len(errors) > 0 {
+			t.Errorf("generateRootPassword() = %q, which is invalid: %v", password, errors)
+		}
+		for _, class := range []string{rootPasswordUpper, rootPasswordLower, rootPasswordDigits, rootPasswordSpecial} {
+			if !strings.ContainsAny(password, class) {
+				t.Errorf("generateRootPassword() = %q, which contains none of %q", password, class)
+			}
+		}
+		passwords[password] = true
+	}
+	if len(passwords) != 100 {
+		t.Errorf("generateRootPassword() returned %d distinct passwords out of 100", len(passwords))
+	}
+}
@@ -0,0 +1,275 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+// instanceUpdateClass tells how a changed argument of an instance is applied.
+type instanceUpdateClass int
+
+const (
+	// instanceUpdateStateOnly arguments are only recorded in the state,
+	// there is nothing to change on the instance itself.
+	instanceUpdateStateOnly instanceUpdateClass = iota
+	// instanceUpdatePatch arguments are changed with PatchInstance.
+	instanceUpdatePatch
+	// instanceUpdateUpgrade arguments are changed with UpgradeInstance.
+	instanceUpdateUpgrade
+	// instanceUpdateReinstall arguments are applied by reinstalling the instance.
+	instanceUpdateReinstall
+	// instanceUpdateForceNew arguments can not be changed, the instance is
+	// replaced.
+	instanceUpdateForceNew
+)
+
+// instanceArgumentUpdateClasses classifies every argument of contabo_instance.
+var instanceArgumentUpdateClasses = map[string]instanceUpdateClass{
+	"display_name":           instanceUpdatePatch,
+	"add_ons":                instanceUpdateUpgrade,
+	"image_id":               instanceUpdateReinstall,
+	"ssh_keys":               instanceUpdateReinstall,
+	"root_password":          instanceUpdateReinstall,
+	"root_password_wo":       instanceUpdateReinstall,
+	"generate_root_password": instanceUpdateReinstall,
+	"user_data":              instanceUpdateReinstall,
+	"default_user":           instanceUpdateReinstall,
+	"region":                 instanceUpdateForceNew,
+	"product_id":             instanceUpdateForceNew,
+	"license":                instanceUpdateForceNew,
+	"period":                 instanceUpdateStateOnly,
+	"cancel_date":            instanceUpdateStateOnly,
+	"on_destroy":             instanceUpdateStateOnly,
+	"wait_for":               instanceUpdateStateOnly,
+	"existing_instance_id":   instanceUpdateStateOnly,
+}
+
+// deferredReinstallArguments are only used while installing. Changing them
+// alone does not reinstall the instance, they are applied on the next
+// reinstallation.
+var deferredReinstallArguments = map[string]bool{
+	"default_user": true,
+}
+
+// unreturnedForceNewArguments are not returned by the API. Imported
+// instances have no value for them in their state, setting one records the
+// value instead of replacing the instance.
+var unreturnedForceNewArguments = map[string]bool{
+	"license": true,
+}
+
+// privateNetworkingAddOnId is the add-on which is booked by the
+// privateNetworking field of the upgrade request.
+const privateNetworkingAddOnId = "1477"
+
+// upgradeableAddOns maps the ids of the add-ons which can be booked for an
+// existing instance to the field of the upgrade request booking them.
+var upgradeableAddOns = map[string]func(*openapi.UpgradeInstanceRequest){
+	privateNetworkingAddOnId: func(upgradeInstanceRequest *openapi.UpgradeInstanceRequest) {
+		privateNetworking := make(map[string]interface{})
+		upgradeInstanceRequest.PrivateNetworking = &privateNetworking
+	},
+	strconv.FormatInt(firewallNetworkAddOnId, 10): setFirewallAddOn,
+}
+
+// instanceUpdatePlan lists the changed arguments of an instance by the way
+// they are applied.
+type instanceUpdatePlan struct {
+	patch     []string
+	upgrade   []string
+	reinstall []string
+	forceNew  []string
+	stateOnly []string
+	// deferred arguments are part of stateOnly and take effect on the next
+	// reinstallation.
+	deferred []string
+}
+
+// planInstanceUpdate classifies the changed arguments of an instance.
+// getChange returns the old and the planned value of an argument, e.g.
+// ResourceData.GetChange.
+func planInstanceUpdate(changed []string, getChange func(string) (interface{}, interface{})) instanceUpdatePlan {
+	var plan instanceUpdatePlan
+	var deferred []string
+
+	sorted := append([]string(nil), changed...)
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		oldValue, newValue := getChange(key)
+		switch instanceArgumentUpdateClasses[key] {
+		case instanceUpdatePatch:
+			plan.patch = append(plan.patch, key)
+		case instanceUpdateUpgrade:
+			plan.upgrade = append(plan.upgrade, key)
+		case instanceUpdateReinstall:
+			if deferredReinstallArguments[key] {
+				deferred = append(deferred, key)
+			} else if isEmptyArgument(newValue) {
+				// removing an installation argument does not change the
+				// installed instance
+				plan.stateOnly = append(plan.stateOnly, key)
+			} else {
+				plan.reinstall = append(plan.reinstall, key)
+			}
+		case instanceUpdateForceNew:
+			if unreturnedForceNewArguments[key] && isEmptyArgument(oldValue) {
+				// the value of an imported instance is unknown
+				plan.stateOnly = append(plan.stateOnly, key)
+			} else {
+				plan.forceNew = append(plan.forceNew, key)
+			}
+		default:
+			plan.stateOnly = append(plan.stateOnly, key)
+		}
+	}
+
+	if len(plan.reinstall) > 0 {
+		plan.reinstall = append(plan.reinstall, deferred...)
+	} else {
+		plan.stateOnly = append(plan.stateOnly, deferred...)
+		plan.deferred = deferred
+	}
+	return plan
+}
+
+func isEmptyArgument(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// changedInstanceArguments returns the arguments of an instance which are
+// changed by the update.
+func changedInstanceArguments(hasChange func(string) bool) []string {
+	var changed []string
+	for key := range instanceArgumentUpdateClasses {
+		if hasChange(key) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// resourceInstanceCustomizeDiff replaces the instance if an argument is
+// changed which the API can not change on an existing instance.
+func resourceInstanceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChange("period") {
+		oldPeriod, newPeriod := d.GetChange("period")
+		if err := checkInstancePeriodChange(oldPeriod.(int), newPeriod.(int)); err != nil {
+			return err
+		}
+	}
+	plan := planInstanceUpdate(changedInstanceArguments(d.HasChange), d.GetChange)
+	for _, key := range plan.forceNew {
+		if err := d.ForceNew(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkInstancePeriodChange rejects changes of the contract period, the API
+// can not change it. The period of imported instances is unknown, it can be
+// set once to record the period of their contract.
+func checkInstancePeriodChange(oldPeriod int, newPeriod int) error {
+	if oldPeriod == 0 || oldPeriod == newPeriod {
+		return nil
+	}
+	return fmt.Errorf("period can not be changed from %d to %d months through the API. Change the contract in the Customer Control Panel, then import the instance again to record the new period", oldPeriod, newPeriod)
+}
+
+// upgradeInstanceAddOns books the add-ons which have been added to an
+// instance. Removed add-ons and add-ons which can not be booked through the
+// API are reported as warnings.
+func upgradeInstanceAddOns(
+	ctx context.Context,
+	client *openapi.APIClient,
+	instanceId int64,
+	oldAddOns []interface{},
+	newAddOns []interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	oldQuantities := addOnQuantities(oldAddOns)
+	newQuantities := addOnQuantities(newAddOns)
+
+	upgradeInstanceRequest := openapi.UpgradeInstanceRequest{}
+	upgrade := false
+	var unsupported []string
+	for id, quantity := range newQuantities {
+		oldQuantity, booked := oldQuantities[id]
+		if booked && oldQuantity == quantity {
+			continue
+		}
+		if setAddOn, ok := upgradeableAddOns[id]; ok && !booked {
+			setAddOn(&upgradeInstanceRequest)
+			upgrade = true
+			continue
+		}
+		unsupported = append(unsupported, id)
+	}
+	for id := range oldQuantities {
+		if _, ok := newQuantities[id]; !ok {
+			unsupported = append(unsupported, id)
+		}
+	}
+	sort.Strings(unsupported)
+
+	if upgrade {
+		_, httpResp, err := client.InstancesApi.
+			UpgradeInstance(ctx, instanceId).
+			XRequestId(uuid.NewV4().String()).
+			UpgradeInstanceRequest(upgradeInstanceRequest).
+			Execute()
+
+		if err != nil {
+			return HandleResponseErrors(diags, httpResp)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Add-ons can not be changed through the API",
+			Detail:   fmt.Sprintf("The add-ons %v of instance %d can only be booked, changed or removed in the Customer Control Panel.", unsupported, instanceId),
+		})
+	}
+	return diags
+}
+
+func addOnQuantities(addOns []interface{}) map[string]int {
+	quantities := make(map[string]int)
+	for _, addOn := range addOns {
+		addOnMap, ok := addOn.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := addOnMap["id"].(string)
+		quantity, _ := addOnMap["quantity"].(int)
+		if id == "" {
+			continue
+		}
+		quantities[id] = quantity
+	}
+	return quantities
+}
@@ -0,0 +1,158 @@
+package contabo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanInstanceUpdate(t *testing.T) {
+	cases := []struct {
+		name    string
+		changed []string
+		old     map[string]interface{}
+		values  map[string]interface{}
+		want    instanceUpdatePlan
+	}{
+		{
+			name:    "display name",
+			changed: []string{"display_name"},
+			values:  map[string]interface{}{"display_name": "web"},
+			want:    instanceUpdatePlan{patch: []string{"display_name"}},
+		},
+		{
+			name:    "display name removed",
+			changed: []string{"display_name"},
+			values:  map[string]interface{}{"display_name": ""},
+			want:    instanceUpdatePlan{patch: []string{"display_name"}},
+		},
+		{
+			name:    "add-ons",
+			changed: []string{"add_ons"},
+			values:  map[string]interface{}{"add_ons": []interface{}{}},
+			want:    instanceUpdatePlan{upgrade: []string{"add_ons"}},
+		},
+		{
+			name:    "image",
+			changed: []string{"image_id"},
+			values:  map[string]interface{}{"image_id": "66abf39a-ba8b-425e-a385-8eb347ceac10"},
+			want:    instanceUpdatePlan{reinstall: []string{"image_id"}},
+		},
+		{
+			name:    "user data removed",
+			changed: []string{"user_data"},
+			values:  map[string]interface{}{"user_data": ""},
+			want:    instanceUpdatePlan{stateOnly: []string{"user_data"}},
+		},
+		{
+			name:    "ssh keys removed",
+			changed: []string{"ssh_keys"},
+			values:  map[string]interface{}{"ssh_keys": []interface{}{}},
+			want:    instanceUpdatePlan{stateOnly: []string{"ssh_keys"}},
+		},
+		{
+			name:    "generated root password disabled",
+			changed: []string{"generate_root_password"},
+			values:  map[string]interface{}{"generate_root_password": false},
+			want:    instanceUpdatePlan{stateOnly: []string{"generate_root_password"}},
+		},
+		{
+			name:    "default user alone",
+			changed: []string{"default_user"},
+			values:  map[string]interface{}{"default_user": "root"},
+			want: instanceUpdatePlan{
+				stateOnly: []string{"default_user"},
+				deferred:  []string{"default_user"},
+			},
+		},
+		{
+			name:    "default user with image",
+			changed: []string{"image_id", "default_user"},
+			values: map[string]interface{}{
+				"image_id":     "66abf39a-ba8b-425e-a385-8eb347ceac10",
+				"default_user": "root",
+			},
+			want: instanceUpdatePlan{reinstall: []string{"image_id", "default_user"}},
+		},
+		{
+			name:    "license",
+			changed: []string{"license"},
+			old:     map[string]interface{}{"license": "cPanel5"},
+			values:  map[string]interface{}{"license": "PleskHost"},
+			want:    instanceUpdatePlan{forceNew: []string{"license"}},
+		},
+		{
+			name:    "license of imported instance",
+			changed: []string{"license"},
+			old:     map[string]interface{}{"license": ""},
+			values:  map[string]interface{}{"license": "PleskHost"},
+			want:    instanceUpdatePlan{stateOnly: []string{"license"}},
+		},
+		{
+			name:    "region and product",
+			changed: []string{"region", "product_id"},
+			values:  map[string]interface{}{"region": "US-east", "product_id": "V47"},
+			want:    instanceUpdatePlan{forceNew: []string{"product_id", "region"}},
+		},
+		{
+			name:    "period",
+			changed: []string{"period"},
+			values:  map[string]interface{}{"period": 12},
+			want:    instanceUpdatePlan{stateOnly: []string{"period"}},
+		},
+		{
+			name:    "mixed",
+			changed: []string{"on_destroy", "display_name", "user_data"},
+			values: map[string]interface{}{
+				"on_destroy":   "prevent",
+				"display_name": "web",
+				"user_data":    "#cloud-config\n",
+			},
+			want: instanceUpdatePlan{
+				patch:     []string{"display_name"},
+				reinstall: []string{"user_data"},
+				stateOnly: []string{"on_destroy"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := planInstanceUpdate(c.changed, func(key string) (interface{}, interface{}) {
+				return c.old[key], c.values[key]
+			})
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("planInstanceUpdate(%v) = %+v, want %+v", c.changed, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCheckInstancePeriodChange(t *testing.T) {
+	cases := []struct {
+		oldPeriod int
+		newPeriod int
+		valid     bool
+	}{
+		{oldPeriod: 1, newPeriod: 1, valid: true},
+		{oldPeriod: 0, newPeriod: 12, valid: true},
+		{oldPeriod: 1, newPeriod: 12, valid: false},
+		{oldPeriod: 12, newPeriod: 3, valid: false},
+	}
+	for _, c := range cases {
+		err := checkInstancePeriodChange(c.oldPeriod, c.newPeriod)
+		if c.valid != (err == nil) {
+			t.Errorf("checkInstancePeriodChange(%d, %d): valid = %t, error %v", c.oldPeriod, c.newPeriod, c.valid, err)
+		}
+	}
+}
+
+func TestInstanceArgumentUpdateClassesCoverSchema(t *testing.T) {
+	for key, attributeSchema := range resourceInstance().Schema {
+		if !attributeSchema.Optional && !attributeSchema.Required {
+			continue
+		}
+		if _, ok := instanceArgumentUpdateClasses[key]; !ok {
+			t.Errorf("argument %q of contabo_instance has no update class", key)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/crypto/ssh"
+)
+
+// cloudInitBootFinished is written by cloud-init once all modules have run.
+const cloudInitBootFinished = "/var/lib/cloud/instance/boot-finished"
+const waitForDialTimeout = 10 * time.Second
+
+func waitForSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Block until the compute instance is reachable after it has been created or reinstalled. Without this block terraform continues as soon as the installation has finished, while the operating system might still be booting.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"tcp_port": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					ValidateFunc: validation.IsPortNumber,
+					Description:  "Wait until this TCP port accepts connections on the primary IPv4 address, e.g. `22`.",
+				},
+				"cloud_init": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Wait until cloud-init has finished by checking for `" + cloudInitBootFinished + "` via SSH. Requires `ssh_private_key`.",
+				},
+				"ssh_user": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "User for the cloud-init check. Defaults to the `default_user` of the instance.",
+				},
+				"ssh_private_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Private key matching one of the `ssh_keys` of the instance, used for the cloud-init check.",
+				},
+				"ssh_port": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      22,
+					ValidateFunc: validation.IsPortNumber,
+					Description:  "SSH port for the cloud-init check. Default is `22`.",
+				},
+				"timeout": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "10m",
+					ValidateFunc: validateDuration,
+					Description:  "How long to wait for the instance to become ready, e.g. `10m`. Default is `10m`.",
+				},
+			},
+		},
+	}
+}
+
+func getWaitFor(d *schema.ResourceData) map[string]interface{} {
+	waitFor := d.Get("wait_for").([]interface{})
+	if len(waitFor) == 0 || waitFor[0] == nil {
+		return nil
+	}
+	return waitFor[0].(map[string]interface{})
+}
+
+// waitForOptions holds the checks of a wait_for block.
+type waitForOptions struct {
+	tcpPort       int
+	cloudInit     bool
+	sshUser       string
+	sshPrivateKey string
+	sshPort       int
+	timeout       time.Duration
+}
+
+// parseWaitFor reads a wait_for block. The ssh_user defaults to
+// defaultUser, the default_user of the instance.
+func parseWaitFor(waitFor map[string]interface{}, defaultUser string) (waitForOptions, error) {
+	timeout, err := time.ParseDuration(waitFor["timeout"].(string))
+	if err != nil {
+		return waitForOptions{}, fmt.Errorf("wait_for.timeout: %w", err)
+	}
+	options := waitForOptions{
+		tcpPort:       waitFor["tcp_port"].(int),
+		cloudInit:     waitFor["cloud_init"].(bool),
+		sshUser:       waitFor["ssh_user"].(string),
+		sshPrivateKey: waitFor["ssh_private_key"].(string),
+		sshPort:       waitFor["ssh_port"].(int),
+		timeout:       timeout,
+	}
+	if options.sshUser == "" {
+		options.sshUser = defaultUser
+	}
+	return options, nil
+}
+
+// validateWaitFor is checked before anything is ordered or reinstalled, so a
+// broken wait_for block does not leave a half finished apply behind.
+func validateWaitFor(d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	waitFor := getWaitFor(d)
+	if waitFor == nil {
+		return diags
+	}
+
+	options, err := parseWaitFor(waitFor, d.Get("default_user").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if options.cloudInit {
+		if _, err := ssh.ParsePrivateKey([]byte(options.sshPrivateKey)); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wait_for.cloud_init requires a valid ssh_private_key",
+				Detail:   fmt.Sprintf("The ssh_private_key could not be parsed: %v", err),
+			})
+		}
+	}
+	return diags
+}
+
+func waitForInstanceReady(ctx context.Context, d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	waitFor := getWaitFor(d)
+	if waitFor == nil {
+		return diags
+	}
+	options, err := parseWaitFor(waitFor, d.Get("default_user").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ip := d.Get("ipv4_address").(string)
+	if ip == "" {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Instance has no IPv4 address to wait for",
+			Detail:   fmt.Sprintf("Instance %s has no primary IPv4 address, wait_for can not be checked.", d.Id()),
+		})
+	}
+
+	deadline := time.Now().Add(options.timeout)
+
+	if options.tcpPort != 0 {
+		address := net.JoinHostPort(ip, strconv.Itoa(options.tcpPort))
+		if err := waitForTcpPort(ctx, address, time.Until(deadline)); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Instance did not become ready",
+				Detail:   fmt.Sprintf("Port %d of instance %s did not accept connections within %s: %v", options.tcpPort, d.Id(), options.timeout, err),
+			})
+		}
+	}
+
+	if options.cloudInit {
+		signer, err := ssh.ParsePrivateKey([]byte(options.sshPrivateKey))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		config := &ssh.ClientConfig{
+			User: options.sshUser,
+			Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			// the host key of a freshly (re-)installed instance is not known yet
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         waitForDialTimeout,
+		}
+		address := net.JoinHostPort(ip, strconv.Itoa(options.sshPort))
+
+		err = resource.RetryContext(ctx, time.Until(deadline), func() *resource.RetryError {
+			return resource.RetryableError(isCloudInitFinished(address, config))
+		})
+		if err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Instance did not become ready",
+				Detail:   fmt.Sprintf("cloud-init on instance %s did not finish within %s: %v", d.Id(), options.timeout, err),
+			})
+		}
+	}
+
+	return diags
+}
+
+// waitForTcpPort retries to connect to address until a connection is
+// accepted or the timeout has passed.
+func waitForTcpPort(ctx context.Context, address string, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		connection, err := net.DialTimeout("tcp", address, waitForDialTimeout)
+		if err != nil {
+			return resource.RetryableError(err)
+		}
+		connection.Close()
+		return nil
+	})
+}
+
+func isCloudInitFinished(address string, config *ssh.ClientConfig) error {
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if err := session.Run("test -f " + cloudInitBootFinished); err != nil {
+		return fmt.Errorf("%s does not exist yet", cloudInitBootFinished)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package contabo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testWaitForData(t *testing.T, waitFor map[string]interface{}) *schema.ResourceData {
+	instanceSchema := map[string]*schema.Schema{
+		"wait_for": waitForSchema(),
+		"default_user": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+	raw := map[string]interface{}{"default_user": "admin"}
+	if waitFor != nil {
+		raw["wait_for"] = []interface{}{waitFor}
+	}
+	return schema.TestResourceDataRaw(t, instanceSchema, raw)
+}
+
+func testSshPrivateKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestParseWaitFor(t *testing.T) {
+	cases := []struct {
+		name    string
+		waitFor map[string]interface{}
+		want    waitForOptions
+	}{
+		{
+			name:    "defaults",
+			waitFor: map[string]interface{}{"tcp_port": 22},
+			want:    waitForOptions{tcpPort: 22, sshUser: "admin", sshPort: 22, timeout: 10 * time.Minute},
+		},
+		{
+			name:    "timeout",
+			waitFor: map[string]interface{}{"tcp_port": 443, "timeout": "90s"},
+			want:    waitForOptions{tcpPort: 443, sshUser: "admin", sshPort: 22, timeout: 90 * time.Second},
+		},
+		{
+			name:    "cloud-init",
+			waitFor: map[string]interface{}{"cloud_init": true, "ssh_user": "root", "ssh_private_key": "key", "ssh_port": 2222},
+			want:    waitForOptions{cloudInit: true, sshUser: "root", sshPrivateKey: "key", sshPort: 2222, timeout: 10 * time.Minute},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := testWaitForData(t, c.waitFor)
+			got, err := parseWaitFor(getWaitFor(d), d.Get("default_user").(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("parseWaitFor() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseWaitForInvalidTimeout(t *testing.T) {
+	waitFor := map[string]interface{}{
+		"tcp_port":        22,
+		"cloud_init":      false,
+		"ssh_user":        "",
+		"ssh_private_key": "",
+		"ssh_port":        22,
+		"timeout":         "soon",
+	}
+	if _, err := parseWaitFor(waitFor, "admin"); err == nil {
+		t.Error("parseWaitFor() accepts the timeout soon")
+	}
+}
+
+func TestGetWaitForWithoutBlock(t *testing.T) {
+	if waitFor := getWaitFor(testWaitForData(t, nil)); waitFor != nil {
+		t.Errorf("getWaitFor() = %v, want nil", waitFor)
+	}
+}
+
+func TestValidateWaitFor(t *testing.T) {
+	cases := []struct {
+		name    string
+		waitFor map[string]interface{}
+		valid   bool
+	}{
+		{name: "no block", valid: true},
+		{name: "tcp port", waitFor: map[string]interface{}{"tcp_port": 22}, valid: true},
+		{name: "cloud-init", waitFor: map[string]interface{}{"cloud_init": true, "ssh_private_key": testSshPrivateKey(t)}, valid: true},
+		{name: "cloud-init without key", waitFor: map[string]interface{}{"cloud_init": true}, valid: false},
+		{name: "cloud-init with invalid key", waitFor: map[string]interface{}{"cloud_init": true, "ssh_private_key": "not a key"}, valid: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diags := validateWaitFor(testWaitForData(t, c.waitFor))
+			if diags.HasError() == c.valid {
+				t.Errorf("validateWaitFor() = %v, valid = %t", diags, c.valid)
+			}
+		})
+	}
+}
+
+// testUnusedAddress returns an address on which no connections are accepted.
+func testUnusedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+func TestWaitForTcpPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	if err := waitForTcpPort(context.Background(), listener.Addr().String(), 5*time.Second); err != nil {
+		t.Errorf("waitForTcpPort() = %v", err)
+	}
+}
+
+func TestWaitForTcpPortOpenedLater(t *testing.T) {
+	address := testUnusedAddress(t)
+	listening := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(time.Second)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			t.Error(err)
+		}
+		listening <- listener
+	}()
+
+	err := waitForTcpPort(context.Background(), address, 30*time.Second)
+	if listener := <-listening; listener != nil {
+		listener.Close()
+	}
+	if err != nil {
+		t.Errorf("waitForTcpPort() = %v", err)
+	}
+}
+
+func TestWaitForTcpPortRefusedUntilTimeout(t *testing.T) {
+	address := testUnusedAddress(t)
+
+	start := time.Now()
+	err := waitForTcpPort(context.Background(), address, time.Second)
+	if err == nil {
+		t.Fatal("waitForTcpPort() succeeded without a listener")
+	}
+	if !strings.Contains(err.Error(), "refused") {
+		t.Errorf("waitForTcpPort() = %v, want the refused connection", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("waitForTcpPort() returned after %s, want about the timeout of 1s", elapsed)
+	}
+}
+
+func TestWaitForTcpPortCanceled(t *testing.T) {
+	address := testUnusedAddress(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := waitForTcpPort(ctx, address, time.Minute); err == nil {
+		t.Error("waitForTcpPort() succeeded with a canceled context")
+	}
+}
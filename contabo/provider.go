@@ -68,6 +68,11 @@ func Provider() *schema.Provider {
 			"contabo_object_storage_bucket": resourceObjectStorageBucket(),
 			"contabo_tag":                   resourceTag(),
 			"contabo_tag_assignment":        resourceTagAssignment(),
+			"contabo_reverse_dns":           resourceReverseDns(),
+			"contabo_vip":                   resourceVip(),
+			"contabo_instance_group":        resourceInstanceGroup(),
+			"contabo_firewall_rule":         resourceFirewallRule(),
+			"contabo_firewall_attachment":   resourceFirewallAttachment(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"contabo_instance":              dataSourceInstance(),
@@ -75,11 +80,20 @@ func Provider() *schema.Provider {
 			"contabo_image":                 dataSourceImage(),
 			"contabo_object_storage":        dataSourceObjectStorage(),
 			"contabo_secret":                dataSourceSecret(),
-			"contabo_firewall":          	 dataSourceFirewall(),
+			"contabo_firewall":              dataSourceFirewall(),
 			"contabo_private_network":       dataSourcePrivateNetwork(),
 			"contabo_object_storage_bucket": dataSourceObjectStorageBucket(),
 			"contabo_tag":                   dataSourceTag(),
 			"contabo_tag_assignment":        dataSourceTagAssignment(),
+			"contabo_cloudinit_config":      dataSourceCloudInitConfig(),
+			"contabo_vip":                   dataSourceVip(),
+			"contabo_instance_audits":       dataSourceInstanceAudits(),
+			"contabo_instances":             dataSourceInstances(),
+			"contabo_images":                dataSourceImages(),
+			"contabo_data_centers":          dataSourceDataCenters(),
+			"contabo_regions":               dataSourceRegions(),
+			"contabo_firewall_rules":        dataSourceFirewallRules(),
+			"contabo_firewalls":             dataSourceFirewalls(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
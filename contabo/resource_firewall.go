@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"contabo.com/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 )
@@ -16,8 +20,20 @@ import (
 var httpConflict409 string = "409 Conflict"
 
 const inboundRuleKey = "inbound"
+const outboundRuleKey = "outbound"
 const firewallNetworkAddOnId int64 = 1501
 
+// firewallAssignConcurrency is the number of instances which are assigned
+// or unassigned at the same time.
+const firewallAssignConcurrency = 5
+
+const firewallInstanceStatusTimeout = 10 * time.Minute
+
+const (
+	firewallInstanceStatusActive = "active"
+	firewallInstanceStatusError  = "error"
+)
+
 type jmap map[string]interface{}
 
 func resourceFirewall() *schema.Resource {
@@ -29,6 +45,7 @@ func resourceFirewall() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceContaboFirewallImport,
 		},
+		CustomizeDiff: customdiff.All(validateFirewallRulesDiff, planFirewallAddOns),
 		SchemaVersion: 0,
 		Schema: map[string]*schema.Schema{
 			"created_date": &schema.Schema{
@@ -61,12 +78,36 @@ func resourceFirewall() *schema.Resource {
 				Type:        schema.TypeSet,
 				Elem:        &schema.Schema{Type: schema.TypeInt},
 				Optional:    true,
-				Description: "Add the instace Ids to the firewall here. If you do not add any instance Ids an empty firewall will be created.",
+				Computed:    true,
+				Description: "Add the instace Ids to the firewall here. If you do not add any instance Ids an empty firewall will be created. Leave it unset if the instances are assigned with `contabo_firewall_attachment`. On changes only the added and removed instances are assigned and unassigned, the provider waits until their status in `instances_status` has settled.",
+			},
+			"auto_enable_addon": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Upgrade instances without the firewall add-on with it before they are assigned to the firewall. The add-on is charged, the instances which will be upgraded are listed in `addon_instance_ids` of the plan and reported with a warning once they have been upgraded. Without it assigning such instances fails.",
+			},
+			"addon_instance_ids": {
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Computed:    true,
+				Description: "The instances which have been upgraded with the firewall add-on because of `auto_enable_addon`.",
+			},
+			"managed_instance_ids": {
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Computed:    true,
+				Description: "The instances which have been assigned to the firewall through `instance_ids`, or which were assigned when the firewall was imported.",
+			},
+			"prevent_destroy_with_unmanaged_instances": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse to delete the firewall while instances are assigned which are not listed in `managed_instance_ids`, e.g. instances assigned outside of terraform. Without it all instances are unassigned before the firewall is deleted.",
 			},
 			"instances_status": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Optional:    true,
 				Description: "The status of every instance in the firewall",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -83,8 +124,7 @@ func resourceFirewall() *schema.Resource {
 						"error_message": {
 							Type:        schema.TypeString,
 							Computed:    true,
-							Optional:    true,
-							Description: "Status of the instance.",
+							Description: "The reason why the instance could not be assigned.",
 						},
 					},
 				},
@@ -95,57 +135,92 @@ func resourceFirewall() *schema.Resource {
 				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"inbound": {
-							Type:        schema.TypeList,
+						inboundRuleKey: {
+							Type:        schema.TypeSet,
+							Set:         hashFirewallRule,
 							Computed:    true,
 							Optional:    true,
-							Description: "Inbound rules for this Firewall",
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"protocol": {
-										Type:        schema.TypeString,
-										Computed:    true,
-										Optional:    true,
-										Description: "Define the protocol for the rule. Allowed protocols are `tcp`, `udp` and `icmp`.",
-									},
-									"action": {
-										Type:        schema.TypeString,
-										Computed:    true,
-										Optional:    true,
-										Description: "Action of the rule, currently there is just `accept`.",
-									},
-									"status": {
-										Type:        schema.TypeString,
-										Optional:    true,
-										Description: "Status of the rule. It can be `active`, or `inactive`.",
-									},
-									"dest_ports": {
-										Type:     schema.TypeSet,
-										Optional: true,
-										Elem:     &schema.Schema{Type: schema.TypeString},
-									},
-									"src_cidr": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												"ipv4": &schema.Schema{
-													Type:        schema.TypeSet,
-													Elem:        &schema.Schema{Type: schema.TypeString},
-													Optional:    true,
-													Description: "Provide allowed IPv4 addresses as string array for this rule",
-												},
-												"ipv6": &schema.Schema{
-													Type:        schema.TypeSet,
-													Elem:        &schema.Schema{Type: schema.TypeString},
-													Optional:    true,
-													Description: "Provide allowed IPv6 addresses as string array for this rule",
-												},
-											},
-										},
-									},
-								},
+							Description: "Inbound rules for this Firewall. Leave `rules` unset if the rules are managed with `contabo_firewall_rule`. There can be at most 50 inbound rules, active rules with the same action matching the same traffic must be merged. Overlapping rules, e.g. the ports `80-90` and `85` of the same protocol and source, are reported with a warning.",
+							Elem:        firewallRuleElem(),
+						},
+						outboundRuleKey: {
+							Type:        schema.TypeSet,
+							Set:         hashFirewallRule,
+							Computed:    true,
+							Optional:    true,
+							Description: "Outbound rules for this Firewall. They have the same shape as the inbound rules and restrict the traffic leaving the instances. There can be at most 50 outbound rules.",
+							Elem:        firewallRuleElem(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// firewallRuleElem returns the schema of an inbound or outbound rule.
+func firewallRuleElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"protocol": {
+				Type:             schema.TypeString,
+				Computed:         true,
+				Optional:         true,
+				ValidateFunc:     validateFirewallProtocol,
+				DiffSuppressFunc: suppressEquivalentProtocol,
+				Description:      "Define the protocol for the rule. Allowed protocols are `tcp`, `udp`, `icmp` and `any`. `any` is equivalent to an empty protocol.",
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "accept",
+				ValidateFunc: validateFirewallAction,
+				Description:  "Action of the rule, currently there is just `accept`.",
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "active",
+				ValidateFunc: validateFirewallRuleStatus,
+				Description:  "Status of the rule. It can be `active`, or `inactive`.",
+			},
+			"dest_ports": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      hashPortRange,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateFunc:     validatePortRange,
+					DiffSuppressFunc: suppressEquivalentPortRange,
+				},
+				Description: "The destination ports or port ranges of the rule, e.g. `22` or `8000-8080`. `22-22` and `22` are equivalent. Ports are between 1 and 65535, only `tcp` and `udp` rules can have ports.",
+			},
+			"src_cidr": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ipv4": &schema.Schema{
+							Type: schema.TypeSet,
+							Set:  hashCidr,
+							Elem: &schema.Schema{
+								Type:             schema.TypeString,
+								ValidateFunc:     validateIpv4Cidr,
+								DiffSuppressFunc: suppressEquivalentCidr,
 							},
+							Optional:    true,
+							Description: "Provide allowed IPv4 addresses as string array for this rule. An address is equivalent to its `/32` network.",
+						},
+						"ipv6": &schema.Schema{
+							Type: schema.TypeSet,
+							Set:  hashCidr,
+							Elem: &schema.Schema{
+								Type:             schema.TypeString,
+								ValidateFunc:     validateIpv6Cidr,
+								DiffSuppressFunc: suppressEquivalentCidr,
+							},
+							Optional:    true,
+							Description: "Provide allowed IPv6 addresses as string array for this rule. An address is equivalent to its `/128` network.",
 						},
 					},
 				},
@@ -170,29 +245,9 @@ func resourceFirewallCreate(
 	createFirewallRequest.Description = &description
 	createFirewallRequest.Status = status
 	createFirewallRequest.Rules = openapi.NewRulesRequestWithDefaults()
-	createFirewallRequest.Rules.Inbound = make([]openapi.FirewallRuleRequest, 0)
-	rules := getFirewallInboundRules(d)
-
-	if len(rules) > 0 {
-		for _, rule := range rules {
-			inboundRuleMap := rule.(map[string]interface{})
-			if inboundRuleMap["action"].(string) != "" && inboundRuleMap["status"].(string) != "" {
-				protocol := inboundRuleMap["protocol"].(string)
-				if strings.EqualFold(protocol, "any") {
-					protocol = ""
-				}
+	createFirewallRequest.Rules.Inbound = buildFirewallRuleRequests(getFirewallRules(d, inboundRuleKey))
+	createFirewallRequest.Rules.Outbound = buildFirewallRuleRequests(getFirewallRules(d, outboundRuleKey))
 
-				action := inboundRuleMap["action"].(string)
-				status := inboundRuleMap["status"].(string)
-				destPorts := getDestPorts(inboundRuleMap)
-				srcCidr := *openapi.NewSrcCidrWithDefaults()
-				srcCidr.SetIpv4(getSrcCidrIpv4Addresses(inboundRuleMap))
-				srcCidr.SetIpv6(getSrcCidrIpv6Addresses(inboundRuleMap))
-				apiInboundRule := openapi.NewFirewallRuleRequest(protocol, destPorts, srcCidr, action, status)
-				createFirewallRequest.Rules.Inbound = append(createFirewallRequest.Rules.Inbound, *apiInboundRule)
-			}
-		}
-	}
 	res, httpResp, err := client.FirewallsApi.
 		CreateFirewall(context.Background()).
 		XRequestId(uuid.NewV4().String()).
@@ -209,35 +264,78 @@ func resourceFirewallCreate(
 		})
 	}
 
-	instancesToAdd := d.Get("instance_ids").(*schema.Set).List()
 	firewallId := res.Data[0].FirewallId
+	d.SetId(firewallId)
 
-	for _, instanceId := range instancesToAdd {
-		instanceIdInt := instanceId.(int)
-		instanceId := int64(instanceIdInt)
-
-		pollInstance(diags, client, instanceId)
-
-		httpResp, err = assignInstanceToFirewall(diags, client, firewallId, instanceId)
-		if err != nil {
-			return HandleResponseErrors(diags, httpResp)
-		}
+	instancesToAdd := d.Get("instance_ids").(*schema.Set).List()
+	upgradedInstanceIds, diags := changeFirewallInstances(ctx, client, firewallId, instancesToAdd, nil, d.Get("auto_enable_addon").(bool))
+	diags = append(diags, firewallAddOnWarning(upgradedInstanceIds)...)
+	diags = append(diags, firewallRulesOverlapWarnings(d)...)
+	if err := setFirewallAddOnInstanceIds(d, upgradedInstanceIds); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err := setFirewallManagedInstanceIds(d, instancesToAdd, nil); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if diags.HasError() {
+		return diags
 	}
+	return append(diags, resourceFirewallRead(ctx, d, m)...)
+}
 
-	d.SetId(res.Data[0].FirewallId)
-	return resourceFirewallRead(ctx, d, m)
+// firewallRulesOverlapWarnings warns about overlapping rules of a firewall.
+func firewallRulesOverlapWarnings(d *schema.ResourceData) diag.Diagnostics {
+	diags := firewallRuleOverlapWarnings(inboundRuleKey, getFirewallRules(d, inboundRuleKey))
+	return append(diags, firewallRuleOverlapWarnings(outboundRuleKey, getFirewallRules(d, outboundRuleKey))...)
 }
 
-func getFirewallInboundRules(d *schema.ResourceData) []interface{} {
+// getFirewallRules returns the inbound or outbound rules of a firewall.
+func getFirewallRules(d *schema.ResourceData, ruleKey string) []interface{} {
 	rules := d.Get("rules").([]interface{})
 
-	if rules != nil && len(rules) > 0 {
-		rslt := rules[0].(map[string]interface{})[inboundRuleKey].([]interface{})
+	if len(rules) > 0 && rules[0] != nil {
+		rslt := rules[0].(map[string]interface{})[ruleKey].(*schema.Set).List()
 		return rslt
 	}
 	return nil
 }
 
+// buildFirewallRuleRequests converts inbound or outbound rules to the API
+// representation. Rules without action or status are skipped.
+func buildFirewallRuleRequests(rules []interface{}) []openapi.FirewallRuleRequest {
+	ruleRequests := make([]openapi.FirewallRuleRequest, 0)
+	for _, rule := range rules {
+		ruleMap := rule.(map[string]interface{})
+		protocol := ruleMap["protocol"].(string)
+		if strings.EqualFold(protocol, "any") {
+			protocol = ""
+		}
+
+		action, status := firewallRuleActionAndStatus(ruleMap)
+		destPorts := getDestPorts(ruleMap)
+		srcCidr := *openapi.NewSrcCidrWithDefaults()
+		srcCidr.SetIpv4(getSrcCidrIpv4Addresses(ruleMap))
+		srcCidr.SetIpv6(getSrcCidrIpv6Addresses(ruleMap))
+		ruleRequest := openapi.NewFirewallRuleRequest(protocol, destPorts, srcCidr, action, status)
+		ruleRequests = append(ruleRequests, *ruleRequest)
+	}
+	return ruleRequests
+}
+
+// firewallRuleActionAndStatus returns the action and status of a rule, with
+// the defaults of the API if they are empty.
+func firewallRuleActionAndStatus(ruleMap map[string]interface{}) (string, string) {
+	action, _ := ruleMap["action"].(string)
+	if action == "" {
+		action = "accept"
+	}
+	status, _ := ruleMap["status"].(string)
+	if status == "" {
+		status = "active"
+	}
+	return action, status
+}
+
 func getSrcCidrIpv4Addresses(inboundRuleMap map[string]interface{}) []string {
 	srcCidrs := inboundRuleMap["src_cidr"].([]interface{})
 	ipv4AddressesStrArr := make([]string, 0)
@@ -265,36 +363,197 @@ func getSrcCidrIpv6Addresses(inboundRuleMap map[string]interface{}) []string {
 	return ipv6AddressesStrArr
 }
 
-func handleFirewallInstanceChanges(diags diag.Diagnostics,
+func handleFirewallInstanceChanges(
+	ctx context.Context,
+	diags diag.Diagnostics,
 	d *schema.ResourceData,
 	client *openapi.APIClient,
 	firewallId string) diag.Diagnostics {
 
-	// Remove instances which are not more in this firewall
+	// only the delta is changed, instances which stay in the firewall keep
+	// being protected
 	old, new := d.GetChange("instance_ids")
-	oldInstanceIds := old.(*schema.Set).List()
-	for _, instanceId := range oldInstanceIds {
-		instanceIdInt := instanceId.(int)
-		instanceId := int64(instanceIdInt)
+	instancesToAdd, instancesToRemove := firewallInstanceDelta(old.(*schema.Set), new.(*schema.Set))
 
-		httpResp, err := unassignInstanceToFirewall(diags, client, firewallId, instanceId)
-		if err != nil {
-			return HandleResponseErrors(diags, httpResp)
+	upgradedInstanceIds, diags := changeFirewallInstances(ctx, client, firewallId, instancesToAdd, instancesToRemove, d.Get("auto_enable_addon").(bool))
+	diags = append(diags, firewallAddOnWarning(upgradedInstanceIds)...)
+	if err := setFirewallAddOnInstanceIds(d, upgradedInstanceIds); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err := setFirewallManagedInstanceIds(d, instancesToAdd, instancesToRemove); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// firewallInstanceDelta returns the instances which have to be added to and
+// removed from a firewall, both ordered by their id.
+func firewallInstanceDelta(oldInstanceIds *schema.Set, newInstanceIds *schema.Set) ([]interface{}, []interface{}) {
+	instancesToAdd := newInstanceIds.Difference(oldInstanceIds).List()
+	instancesToRemove := oldInstanceIds.Difference(newInstanceIds).List()
+	for _, instanceIds := range [][]interface{}{instancesToAdd, instancesToRemove} {
+		sort.Slice(instanceIds, func(i, j int) bool {
+			return instanceIds[i].(int) < instanceIds[j].(int)
+		})
+	}
+	return instancesToAdd, instancesToRemove
+}
+
+// setFirewallManagedInstanceIds records the instances assigned through
+// instance_ids in managed_instance_ids.
+func setFirewallManagedInstanceIds(d *schema.ResourceData, addedInstanceIds []interface{}, removedInstanceIds []interface{}) error {
+	managedInstanceIds, _ := d.GetChange("managed_instance_ids")
+	keptInstanceIds := managedInstanceIds.(*schema.Set).Difference(schema.NewSet(schema.HashInt, removedInstanceIds))
+	return d.Set("managed_instance_ids", mergeInstanceIds(keptInstanceIds, instanceIdsOf(addedInstanceIds)))
+}
+
+// setFirewallAddOnInstanceIds adds the instances which have been upgraded
+// with the firewall add-on to addon_instance_ids.
+func setFirewallAddOnInstanceIds(d *schema.ResourceData, upgradedInstanceIds []int) error {
+	addOnInstanceIds, _ := d.GetChange("addon_instance_ids")
+	return d.Set("addon_instance_ids", mergeInstanceIds(addOnInstanceIds.(*schema.Set), upgradedInstanceIds))
+}
+
+// changeFirewallInstances assigns and unassigns instances in parallel and
+// waits until their status in the firewall has settled. New instances are
+// assigned before others are removed. With autoEnableAddOn instances are
+// upgraded with the firewall add-on first if needed, the upgraded instances
+// are returned.
+func changeFirewallInstances(
+	ctx context.Context,
+	client *openapi.APIClient,
+	firewallId string,
+	instancesToAdd []interface{},
+	instancesToRemove []interface{},
+	autoEnableAddOn bool,
+) ([]int, diag.Diagnostics) {
+	if len(instancesToAdd) == 0 && len(instancesToRemove) == 0 {
+		return nil, nil
+	}
+
+	upgradedInstanceIds, diags := assignFirewallInstances(ctx, client, firewallId, instancesToAdd, instancesToRemove, autoEnableAddOn)
+	if diags.HasError() {
+		return upgradedInstanceIds, diags
+	}
+
+	// the lock is not held while waiting, other resources can change the
+	// firewall in the meantime
+	return upgradedInstanceIds, waitForFirewallInstancesStatus(ctx, client, firewallId, instanceIdsOf(instancesToAdd), instanceIdsOf(instancesToRemove))
+}
+
+// assignFirewallInstances is changeFirewallInstances without waiting for the
+// status of the instances. It holds the lock of the firewall.
+func assignFirewallInstances(
+	ctx context.Context,
+	client *openapi.APIClient,
+	firewallId string,
+	instancesToAdd []interface{},
+	instancesToRemove []interface{},
+	autoEnableAddOn bool,
+) ([]int, diag.Diagnostics) {
+	defer lockFirewall(firewallId)()
+
+	var upgradedInstanceIds []int
+	var mutex sync.Mutex
+	diags := runInBatches(instanceIdsOf(instancesToAdd), firewallAssignConcurrency, func(instanceId int) diag.Diagnostics {
+		if autoEnableAddOn {
+			upgraded, diags := enableFirewallAddOn(ctx, client, int64(instanceId))
+			if diags.HasError() {
+				return diags
+			}
+			if upgraded {
+				mutex.Lock()
+				upgradedInstanceIds = append(upgradedInstanceIds, instanceId)
+				mutex.Unlock()
+
+				// the upgrade restarts the instance
+				if diags := pollInstance(nil, client, int64(instanceId)); diags.HasError() {
+					return diags
+				}
+			}
 		}
+		return retryOnFirewallConflict(ctx, firewallId, func() (*http.Response, error) {
+			return assignInstanceToFirewall(nil, client, firewallId, int64(instanceId))
+		})
+	})
+	if diags.HasError() {
+		return upgradedInstanceIds, diags
 	}
 
-	// Add new instances which are now in this firewall
-	newInstanceIds := new.(*schema.Set).List()
-	for _, instanceId := range newInstanceIds {
-		instanceIdInt := instanceId.(int)
-		instanceId := int64(instanceIdInt)
+	diags = runInBatches(instanceIdsOf(instancesToRemove), firewallAssignConcurrency, func(instanceId int) diag.Diagnostics {
+		return retryOnFirewallConflict(ctx, firewallId, func() (*http.Response, error) {
+			httpResp, err := unassignInstanceToFirewall(nil, client, firewallId, int64(instanceId))
+			if isNotFound(httpResp) {
+				// the instance is already gone
+				return httpResp, nil
+			}
+			return httpResp, err
+		})
+	})
+	return upgradedInstanceIds, diags
+}
 
-		httpResp, err := assignInstanceToFirewall(diags, client, firewallId, instanceId)
+// waitForFirewallInstancesStatus polls the firewall until the added
+// instances are active and the removed instances are no longer listed in
+// instances_status.
+func waitForFirewallInstancesStatus(
+	ctx context.Context,
+	client *openapi.APIClient,
+	firewallId string,
+	addedInstanceIds []int,
+	removedInstanceIds []int,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	err := resource.RetryContext(ctx, firewallInstanceStatusTimeout, func() *resource.RetryError {
+		res, httpResp, err := client.FirewallsApi.
+			RetrieveFirewall(ctx, firewallId).
+			XRequestId(uuid.NewV4().String()).
+			Execute()
 		if err != nil {
-			return HandleResponseErrors(diags, httpResp)
+			return resource.NonRetryableError(DiagnosticsToError(HandleResponseErrors(nil, httpResp)))
+		} else if len(res.Data) != 1 {
+			return resource.NonRetryableError(DiagnosticsToError(MultipleDataObjectsError(nil)))
+		}
+
+		statuses := make(map[int64]string)
+		errorMessages := make(map[int64]string)
+		for _, instanceStatus := range res.Data[0].InstanceStatus {
+			statuses[instanceStatus.GetInstanceId()] = string(instanceStatus.GetStatus())
+			errorMessages[instanceStatus.GetInstanceId()] = instanceStatus.GetErrorMessage()
 		}
+		for _, instanceId := range addedInstanceIds {
+			status, ok := statuses[int64(instanceId)]
+			if ok && strings.EqualFold(status, firewallInstanceStatusError) {
+				return resource.NonRetryableError(fmt.Errorf("instance %d could not be assigned to firewall %s: %s", instanceId, firewallId, errorMessages[int64(instanceId)]))
+			}
+			if !ok || !strings.EqualFold(status, firewallInstanceStatusActive) {
+				return resource.RetryableError(fmt.Errorf("instance %d is not active in firewall %s yet", instanceId, firewallId))
+			}
+		}
+		for _, instanceId := range removedInstanceIds {
+			if _, ok := statuses[int64(instanceId)]; ok {
+				return resource.RetryableError(fmt.Errorf("instance %d is still listed in firewall %s", instanceId, firewallId))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Firewall instances did not settle",
+			Detail:   err.Error(),
+		})
 	}
-	return nil
+	return diags
+}
+
+func instanceIdsOf(instanceIds []interface{}) []int {
+	ids := make([]int, 0, len(instanceIds))
+	for _, instanceId := range instanceIds {
+		ids = append(ids, instanceId.(int))
+	}
+	return ids
 }
 
 func getDestPorts(
@@ -354,17 +613,19 @@ func resourceFirewallUpdate(
 	firewallId := d.Id()
 
 	if d.HasChange("instance_ids") {
-		rsltDiag := handleFirewallInstanceChanges(diags, d, client, firewallId)
-		if rsltDiag != nil {
+		rsltDiag := handleFirewallInstanceChanges(ctx, diags, d, client, firewallId)
+		if rsltDiag.HasError() {
 			return rsltDiag
 		}
+		diags = append(diags, rsltDiag...)
 	}
 
 	if d.HasChange("rules") {
-		rsltDiag := handleFirewallRulesChanges(diags, d, client, firewallId)
+		rsltDiag := handleFirewallRulesChanges(ctx, diags, d, client, firewallId)
 		if rsltDiag != nil {
 			return rsltDiag
 		}
+		diags = append(diags, firewallRulesOverlapWarnings(d)...)
 	}
 
 	var updateFirewallRequest openapi.PatchFirewallRequest
@@ -393,54 +654,52 @@ func resourceFirewallUpdate(
 		if err != nil {
 			return HandleResponseErrors(diags, httpResp)
 		}
-
-		d.Set("updated_at", time.Now().Format(time.RFC850))
-		return resourceFirewallRead(ctx, d, m)
 	}
-	return diags
+	return append(diags, resourceFirewallRead(ctx, d, m)...)
 }
 
 func handleFirewallRulesChanges(
+	ctx context.Context,
 	diags diag.Diagnostics,
 	d *schema.ResourceData,
 	client *openapi.APIClient,
 	firewallId string) diag.Diagnostics {
-	rules := getFirewallInboundRules(d)
-	firewallRulesRequest := *openapi.NewPutFirewallRequestWithDefaults()
-	firewallRulesRequest.Rules = openapi.NewRulesRequestWithDefaults()
-	firewallRulesRequest.Rules.Inbound = make([]openapi.FirewallRuleRequest, 0)
-
-	if len(rules) > 0 {
-		for _, rule := range rules {
-			inboundRuleMap := rule.(map[string]interface{})
-			if inboundRuleMap["action"].(string) != "" && inboundRuleMap["status"].(string) != "" {
-				protocol := inboundRuleMap["protocol"].(string)
-				if strings.EqualFold(protocol, "any") {
-					protocol = ""
-				}
-
-				action := inboundRuleMap["action"].(string)
-				status := inboundRuleMap["status"].(string)
-				destPorts := getDestPorts(inboundRuleMap)
-				srcCidr := *openapi.NewSrcCidrWithDefaults()
-				srcCidr.SetIpv4(getSrcCidrIpv4Addresses(inboundRuleMap))
-				srcCidr.SetIpv6(getSrcCidrIpv6Addresses(inboundRuleMap))
-				apiInboundRule := openapi.NewFirewallRuleRequest(protocol, destPorts, srcCidr, action, status)
-				firewallRulesRequest.Rules.Inbound = append(firewallRulesRequest.Rules.Inbound, *apiInboundRule)
-			}
-		}
-	}
-	_, httpResp, err := client.FirewallsApi.
-		PutFirewall(context.Background(), firewallId).
-		XRequestId(uuid.NewV4().String()).
-		PutFirewallRequest(firewallRulesRequest).
-		Execute()
+	// rules is authoritative, the current rules are replaced
+	inbound := buildFirewallRuleRequests(getFirewallRules(d, inboundRuleKey))
+	outbound := buildFirewallRuleRequests(getFirewallRules(d, outboundRuleKey))
+	err := updateFirewallRules(ctx, client, firewallId, func(rules *openapi.RulesRequest) error {
+		rules.Inbound = inbound
+		rules.Outbound = outbound
+		return nil
+	}, func(rules *openapi.RulesRequest) bool {
+		return sameFirewallRules(rules.Inbound, inbound) && sameFirewallRules(rules.Outbound, outbound)
+	})
 	if err != nil {
-		return HandleResponseErrors(diags, httpResp)
+		return diag.FromErr(err)
 	}
 	return nil
 }
 
+// sameFirewallRules tells whether two lists hold the same rules with the same
+// action and status, their order is ignored.
+func sameFirewallRules(rules []openapi.FirewallRuleRequest, otherRules []openapi.FirewallRuleRequest) bool {
+	if len(rules) != len(otherRules) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, rule := range rules {
+		counts[firewallRuleRequestState(rule)]++
+	}
+	for _, rule := range otherRules {
+		key := firewallRuleRequestState(rule)
+		if counts[key] == 0 {
+			return false
+		}
+		counts[key]--
+	}
+	return true
+}
+
 func resourceFirewallDelete(
 	ctx context.Context,
 	d *schema.ResourceData,
@@ -450,30 +709,94 @@ func resourceFirewallDelete(
 	client := m.(*openapi.APIClient)
 	firewallId := d.Id()
 
+	defer lockFirewall(firewallId)()
+
 	readRes, httpResp, err := client.FirewallsApi.
 		RetrieveFirewall(ctx, firewallId).
 		XRequestId(uuid.NewV4().String()).
 		Execute()
-	if err != nil {
+	if isNotFound(httpResp) {
+		// the firewall has already been deleted
+		d.SetId("")
+		return diags
+	} else if err != nil {
 		return HandleResponseErrors(diags, httpResp)
+	} else if len(readRes.Data) == 0 {
+		d.SetId("")
+		return diags
+	} else if len(readRes.Data) > 1 {
+		return MultipleDataObjectsError(diags)
 	}
 
-	for _, i := range readRes.Data[0].Instances {
-		client.FirewallsApi.UnassignInstanceFirewall(ctx, firewallId, i.InstanceId).XRequestId(uuid.NewV4().String()).Execute()
+	var instanceIds []int
+	for _, instance := range readRes.Data[0].Instances {
+		instanceIds = append(instanceIds, int(instance.InstanceId))
 	}
 
-	httpResp, err = client.FirewallsApi.
-		DeleteFirewall(ctx, firewallId).
-		XRequestId(uuid.NewV4().String()).
-		Execute()
+	if d.Get("prevent_destroy_with_unmanaged_instances").(bool) {
+		if unmanaged := unmanagedFirewallInstances(instanceIds, d.Get("managed_instance_ids").(*schema.Set)); len(unmanaged) > 0 {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Firewall has unmanaged instances",
+				Detail:   fmt.Sprintf("Firewall %s is not deleted because the instances %v have not been assigned by terraform. Unassign them or set prevent_destroy_with_unmanaged_instances to false.", firewallId, unmanaged),
+			})
+		}
+	}
 
-	if err != nil {
-		return HandleResponseErrors(diags, httpResp)
+	// the failures of a batch are reported together, the firewall is only
+	// deleted once all instances have been unassigned
+	unassignDiags := runInBatches(instanceIds, firewallAssignConcurrency, func(instanceId int) diag.Diagnostics {
+		unassignDiags := retryOnFirewallConflict(ctx, firewallId, func() (*http.Response, error) {
+			httpResp, err := unassignInstanceToFirewall(nil, client, firewallId, int64(instanceId))
+			if isNotFound(httpResp) {
+				// the instance is already gone
+				return httpResp, nil
+			}
+			return httpResp, err
+		})
+		if unassignDiags.HasError() {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Could not unassign instance %d from firewall %s", instanceId, firewallId),
+				Detail:   DiagnosticsToError(unassignDiags).Error(),
+			}}
+		}
+		return nil
+	})
+	if unassignDiags.HasError() {
+		return append(diags, unassignDiags...)
+	}
+
+	diags = retryOnFirewallConflict(ctx, firewallId, func() (*http.Response, error) {
+		httpResp, err := client.FirewallsApi.
+			DeleteFirewall(ctx, firewallId).
+			XRequestId(uuid.NewV4().String()).
+			Execute()
+		if isNotFound(httpResp) {
+			return httpResp, nil
+		}
+		return httpResp, err
+	})
+	if diags.HasError() {
+		return diags
 	}
 	d.SetId("")
 	return diags
 }
 
+// unmanagedFirewallInstances returns the instances which have not been
+// assigned through managed_instance_ids.
+func unmanagedFirewallInstances(instanceIds []int, managedInstanceIds *schema.Set) []int {
+	var unmanaged []int
+	for _, instanceId := range instanceIds {
+		if !managedInstanceIds.Contains(instanceId) {
+			unmanaged = append(unmanaged, instanceId)
+		}
+	}
+	sort.Ints(unmanaged)
+	return unmanaged
+}
+
 func assignInstanceToFirewall(
 	diags diag.Diagnostics,
 	client *openapi.APIClient,
@@ -501,28 +824,47 @@ func unassignInstanceToFirewall(
 	return httpResp, err
 }
 
+// resourceContaboFirewallImport imports a firewall by its id or, if there is
+// no firewall with this id, by its name.
 func resourceContaboFirewallImport(
 	ctx context.Context,
 	d *schema.ResourceData,
 	m interface{}) ([]*schema.ResourceData, error) {
-	var diags diag.Diagnostics
 	client := m.(*openapi.APIClient)
 	firewallId := d.Id()
 
-	res, httpResp, err := client.FirewallsApi.
+	_, httpResp, err := client.FirewallsApi.
 		RetrieveFirewall(ctx, firewallId).
 		XRequestId(uuid.NewV4().String()).
 		Execute()
-	if err != nil {
-		HandleResponseErrors(diags, httpResp)
+	if isNotFound(httpResp) || isBadRequest(httpResp) {
+		var diags diag.Diagnostics
+		firewallId, diags = findFirewallByName(ctx, client, d.Id(), "")
+		if diags.HasError() {
+			return nil, DiagnosticsToError(diags)
+		}
+	} else if err != nil {
+		return nil, DiagnosticsToError(HandleResponseErrors(nil, httpResp))
+	}
+
+	d.SetId(firewallId)
+	if err := d.Set("auto_enable_addon", false); err != nil {
 		return nil, err
 	}
-	if len(res.Data) != 1 {
-		return nil, fmt.Errorf("should have returned only one object: %v", err)
+	if err := d.Set("addon_instance_ids", []int{}); err != nil {
+		return nil, err
 	}
-
-	_, resourceData := AddFirewallToData(res.Data[0], d, diags)
-	return []*schema.ResourceData{resourceData}, nil
+	if err := d.Set("prevent_destroy_with_unmanaged_instances", false); err != nil {
+		return nil, err
+	}
+	if diags := resourceFirewallRead(ctx, d, m); diags.HasError() {
+		return nil, DiagnosticsToError(diags)
+	}
+	// the instances assigned at import time are managed from now on
+	if err := d.Set("managed_instance_ids", d.Get("instance_ids").(*schema.Set).List()); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
 }
 
 func AddFirewallToData(
@@ -530,32 +872,23 @@ func AddFirewallToData(
 	d *schema.ResourceData,
 	diags diag.Diagnostics,
 ) (diag.Diagnostics, *schema.ResourceData) {
-	id := firewall.FirewallId
-	if err := d.Set("id", id); err != nil {
-		return diag.FromErr(err), nil
-	}
-	if err := d.Set("name", firewall.Name); err != nil {
-		return diag.FromErr(err), nil
-	}
-	if err := d.Set("status", firewall.Status); err != nil {
-		return diag.FromErr(err), nil
-	}
-	if err := d.Set("description", firewall.Description); err != nil {
-		return diag.FromErr(err), nil
+	for key, value := range flattenFirewall(firewall) {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err), nil
+		}
 	}
+	return diags, d
+}
+
+// flattenFirewall returns the attributes of a firewall which are shared by
+// the resource and the data sources.
+func flattenFirewall(firewall openapi.FirewallResponse) map[string]interface{} {
 	var instanceIds []int64
 	for _, instance := range firewall.Instances {
 		instanceIds = append(instanceIds, instance.InstanceId)
 	}
-	if err := d.Set("instance_ids", instanceIds); err != nil {
-		return diag.FromErr(err), nil
-	}
-	rules := buildFirewallRules(&firewall.Rules)
-	if err := d.Set("rules", rules); err != nil {
-		return diag.FromErr(err), nil
-	}
-	var instancesStatus []interface{}
 
+	var instancesStatus []interface{}
 	for _, instanceStatus := range firewall.InstanceStatus {
 		newStatus := make(map[string]interface{})
 		newStatus["instance_id"] = instanceStatus.InstanceId
@@ -565,58 +898,57 @@ func AddFirewallToData(
 		instancesStatus = append(instancesStatus, newStatus)
 	}
 
-	if err := d.Set("instances_status", instancesStatus); err != nil {
-		return diag.FromErr(err), nil
-	}
-	createdAt := firewall.CreatedDate.Format(time.RFC850)
-	if err := d.Set("created_date", createdAt); err != nil {
-		return diag.FromErr(err), nil
+	return map[string]interface{}{
+		"id":               firewall.FirewallId,
+		"name":             firewall.Name,
+		"status":           firewall.Status,
+		"description":      firewall.GetDescription(),
+		"instance_ids":     instanceIds,
+		"rules":            buildFirewallRules(&firewall.Rules),
+		"instances_status": instancesStatus,
+		"created_date":     firewall.CreatedDate.Format(time.RFC850),
 	}
-	return diags, d
 }
 
 func buildFirewallRules(rulesResponse *openapi.Rules) []interface{} {
 	if rulesResponse != nil {
 		var sliceOfRules = make([]interface{}, 0)
-		var ruleList = make([]interface{}, 0)
-
-		inboundRules := make(map[string]interface{}, 0)
-		for _, ruleInbound := range rulesResponse.Inbound {
-			rule := make(map[string]interface{})
-
-			rule["protocol"] = ruleInbound.Protocol
-			rule["dest_ports"] = ruleInbound.DestPorts
-			rule["status"] = ruleInbound.Status
-			rule["action"] = ruleInbound.Action
 
-			var srcCidrs []interface{}
-			srcCidrMap := make(map[string]interface{})
-			if ruleInbound.SrcCidr.Ipv4 != nil {
-				srcCidrMap["ipv4"] = buildIps(*ruleInbound.SrcCidr.Ipv4)
-			}
-			if ruleInbound.SrcCidr.Ipv6 != nil {
-				srcCidrMap["ipv6"] = buildIps(*ruleInbound.SrcCidr.Ipv6)
-			}
-			srcCidrs = append(srcCidrs, srcCidrMap)
-			rule["src_cidr"] = srcCidrs
-
-			ruleList = append(ruleList, rule)
-		}
-		inboundRules["inbound"] = ruleList
-		sliceOfRules = append(sliceOfRules, inboundRules)
+		rules := make(map[string]interface{}, 0)
+		rules[inboundRuleKey] = buildFirewallRuleList(rulesResponse.Inbound)
+		rules[outboundRuleKey] = buildFirewallRuleList(rulesResponse.Outbound)
+		sliceOfRules = append(sliceOfRules, rules)
 
 		return sliceOfRules
 	}
 	return nil
 }
 
-func buildIps(ips []string) *schema.Set {
-	interfaceIp := make([]interface{}, len(ips))
-	if !(len(ips) > 0) {
-		return schema.NewSet(schema.HashString, interfaceIp)
-	}
-	for i := range ips {
-		interfaceIp[i] = ips[i]
+func buildFirewallRuleList(rulesResponse []openapi.FirewallRuleResponse) []interface{} {
+	var ruleList = make([]interface{}, 0)
+	for _, ruleResponse := range rulesResponse {
+		rule := make(map[string]interface{})
+
+		rule["protocol"] = ruleResponse.Protocol
+		rule["dest_ports"] = ruleResponse.DestPorts
+		rule["status"] = ruleResponse.Status
+		rule["action"] = ruleResponse.Action
+
+		var srcCidrs []interface{}
+		srcCidrMap := make(map[string]interface{})
+		if ruleResponse.SrcCidr.Ipv4 != nil {
+			srcCidrMap["ipv4"] = *ruleResponse.SrcCidr.Ipv4
+		}
+		if ruleResponse.SrcCidr.Ipv6 != nil {
+			srcCidrMap["ipv6"] = *ruleResponse.SrcCidr.Ipv6
+		}
+		// rules without addresses have no src_cidr block in the configuration
+		if len(ruleResponse.SrcCidr.GetIpv4()) > 0 || len(ruleResponse.SrcCidr.GetIpv6()) > 0 {
+			srcCidrs = append(srcCidrs, srcCidrMap)
+		}
+		rule["src_cidr"] = srcCidrs
+
+		ruleList = append(ruleList, rule)
 	}
-	return schema.NewSet(schema.HashString, interfaceIp)
+	return ruleList
 }
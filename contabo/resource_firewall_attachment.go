@@ -0,0 +1,248 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+)
+
+func resourceFirewallAttachment() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Assigns a compute instance to a firewall. Use it to attach instances to a firewall which is managed elsewhere, the other instances of the firewall are kept. Do not set `instance_ids` of a `contabo_firewall` whose instances are managed with this resource.",
+		CreateContext: resourceFirewallAttachmentCreate,
+		ReadContext:   resourceFirewallAttachmentRead,
+		UpdateContext: resourceFirewallAttachmentUpdate,
+		DeleteContext: resourceFirewallAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFirewallAttachmentImport,
+		},
+		CustomizeDiff: planFirewallAttachmentAddOn,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The identifier of the attachment: `<firewall id>/<instance id>`.",
+			},
+			"firewall_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The identifier of the firewall.",
+			},
+			"instance_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The identifier of the compute instance.",
+			},
+			"auto_enable_addon": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Upgrade the instance with the firewall add-on before it is assigned, if it does not have it yet. The add-on is charged, whether the instance will be upgraded is shown by `addon_enabled` in the plan and a warning is reported once it has been upgraded. Without it assigning such an instance fails.",
+			},
+			"addon_enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the instance has been upgraded with the firewall add-on because of `auto_enable_addon`.",
+			},
+		},
+	}
+}
+
+func resourceFirewallAttachmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	firewallId := d.Get("firewall_id").(string)
+	instanceId := int64(d.Get("instance_id").(int))
+
+	var diags diag.Diagnostics
+	upgraded := false
+	if d.Get("auto_enable_addon").(bool) {
+		var addOnDiags diag.Diagnostics
+		upgraded, addOnDiags = enableFirewallAddOn(ctx, client, instanceId)
+		if addOnDiags.HasError() {
+			return addOnDiags
+		}
+		if upgraded {
+			diags = firewallAddOnWarning([]int{int(instanceId)})
+
+			// the upgrade restarts the instance
+			if pollDiags := pollInstance(nil, client, instanceId); pollDiags.HasError() {
+				return append(diags, pollDiags...)
+			}
+		}
+	}
+	if err := d.Set("addon_enabled", upgraded); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	assignDiags := retryFirewallConflicts(ctx, firewallId, func() (*http.Response, error) {
+		httpResp, err := assignInstanceToFirewall(nil, client, firewallId, instanceId)
+		if err != nil {
+			return httpResp, err
+		}
+		return checkFirewallInstance(ctx, client, firewallId, instanceId, true)
+	})
+	diags = append(diags, assignDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%s/%d", firewallId, instanceId))
+	return append(diags, resourceFirewallAttachmentRead(ctx, d, m)...)
+}
+
+func resourceFirewallAttachmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	firewallId, instanceId, err := parseFirewallAttachmentId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	res, httpResp, err := client.FirewallsApi.
+		RetrieveFirewall(ctx, firewallId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if isNotFound(httpResp) {
+		d.SetId("")
+		return diags
+	} else if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) != 1 {
+		return MultipleDataObjectsError(diags)
+	}
+
+	for _, instance := range res.Data[0].Instances {
+		if instance.InstanceId == instanceId {
+			if err := d.Set("firewall_id", firewallId); err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("instance_id", int(instanceId)); err != nil {
+				return diag.FromErr(err)
+			}
+			return diags
+		}
+	}
+
+	// the instance has been unassigned outside of terraform
+	d.SetId("")
+	return diags
+}
+
+// resourceFirewallAttachmentUpdate only stores auto_enable_addon, it has no
+// effect on an instance which is already assigned.
+func resourceFirewallAttachmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceFirewallAttachmentRead(ctx, d, m)
+}
+
+func resourceFirewallAttachmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	firewallId, instanceId, err := parseFirewallAttachmentId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := retryFirewallConflicts(ctx, firewallId, func() (*http.Response, error) {
+		httpResp, err := unassignInstanceToFirewall(nil, client, firewallId, instanceId)
+		if isNotFound(httpResp) {
+			// the instance or the firewall is already gone
+			return httpResp, nil
+		} else if err != nil {
+			return httpResp, err
+		}
+		httpResp, err = checkFirewallInstance(ctx, client, firewallId, instanceId, false)
+		if isNotFound(httpResp) {
+			return httpResp, nil
+		}
+		return httpResp, err
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+	return diags
+}
+
+// checkFirewallInstance reads a firewall again and returns
+// errFirewallChangeLost unless the instance is assigned to it as expected.
+func checkFirewallInstance(ctx context.Context, client *openapi.APIClient, firewallId string, instanceId int64, assigned bool) (*http.Response, error) {
+	res, httpResp, err := client.FirewallsApi.
+		RetrieveFirewall(ctx, firewallId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if err != nil {
+		return httpResp, err
+	} else if len(res.Data) != 1 {
+		return httpResp, DiagnosticsToError(MultipleDataObjectsError(nil))
+	}
+
+	for _, instance := range res.Data[0].Instances {
+		if instance.InstanceId == instanceId {
+			if !assigned {
+				return httpResp, errFirewallChangeLost
+			}
+			return httpResp, nil
+		}
+	}
+	if assigned {
+		return httpResp, errFirewallChangeLost
+	}
+	return httpResp, nil
+}
+
+func resourceFirewallAttachmentImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := parseFirewallAttachmentId(d.Id()); err != nil {
+		return nil, err
+	}
+	if err := d.Set("auto_enable_addon", false); err != nil {
+		return nil, err
+	}
+	if err := d.Set("addon_enabled", false); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// planFirewallAttachmentAddOn shows in the plan of a new attachment whether
+// the instance will be upgraded with the firewall add-on. If its add-ons can
+// not be retrieved, e.g. because the instance does not exist yet,
+// addon_enabled is unknown until the apply.
+func planFirewallAttachmentAddOn(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" {
+		return nil
+	}
+	if !d.Get("auto_enable_addon").(bool) {
+		return d.SetNew("addon_enabled", false)
+	}
+	if !d.NewValueKnown("instance_id") {
+		return d.SetNewComputed("addon_enabled")
+	}
+
+	hasAddOn, diags := instanceHasFirewallAddOn(ctx, m.(*openapi.APIClient), int64(d.Get("instance_id").(int)))
+	if diags.HasError() {
+		return d.SetNewComputed("addon_enabled")
+	}
+	return d.SetNew("addon_enabled", !hasAddOn)
+}
+
+func parseFirewallAttachmentId(id string) (string, int64, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) == 2 && parts[0] != "" {
+		if instanceId, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			return parts[0], instanceId, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid firewall attachment id %q, expected <firewall id>/<instance id>", id)
+}
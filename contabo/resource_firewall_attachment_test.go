@@ -0,0 +1,49 @@
+package contabo
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccContaboFirewallAttachmentBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboFirewallAttachmentConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("contabo_firewall_attachment.attachment_test", "instance_id", "contabo_instance.attachment_test", "id"),
+					resource.TestCheckResourceAttr("contabo_firewall_attachment.attachment_test", "addon_enabled", "false"),
+				),
+			},
+			{
+				ResourceName:      "contabo_firewall_attachment.attachment_test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckContaboFirewallAttachmentConfig() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "attachment_test" {
+			display_name = "firewall attachment"
+		}
+
+		resource "contabo_firewall" "attachment_test" {
+			name   = "terraform-firewall-attachment"
+			status = "active"
+		}
+
+		resource "contabo_firewall_attachment" "attachment_test" {
+			firewall_id = contabo_firewall.attachment_test.id
+			instance_id = contabo_instance.attachment_test.id
+		}
+	`
+}
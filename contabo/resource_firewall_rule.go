@@ -0,0 +1,358 @@
+package contabo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+func resourceFirewallRule() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A single inbound or outbound rule of a firewall. Use it to contribute rules to a firewall which is managed elsewhere, e.g. from several modules. The other rules of the firewall are kept, do not set `rules` of a `contabo_firewall` whose rules are managed with this resource.",
+		CreateContext: resourceFirewallRuleCreate,
+		ReadContext:   resourceFirewallRuleRead,
+		UpdateContext: resourceFirewallRuleUpdate,
+		DeleteContext: resourceFirewallRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFirewallRuleImport,
+		},
+		CustomizeDiff: resourceFirewallRuleCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The identifier of the rule: `<firewall id>/<direction>/<hash of the rule>`.",
+			},
+			"firewall_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The identifier of the firewall the rule belongs to.",
+			},
+			"direction": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      inboundRuleKey,
+				ValidateFunc: validation.StringInSlice([]string{inboundRuleKey, outboundRuleKey}, false),
+				Description:  "Whether the rule is an `inbound` or an `outbound` rule.",
+			},
+			"protocol": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "any",
+				ValidateFunc:     validateFirewallProtocol,
+				DiffSuppressFunc: suppressEquivalentProtocol,
+				Description:      "Define the protocol for the rule. Allowed protocols are `tcp`, `udp`, `icmp` and `any`.",
+			},
+			"dest_ports": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Set:      hashPortRange,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateFunc:     validatePortRange,
+					DiffSuppressFunc: suppressEquivalentPortRange,
+				},
+				Description: "The destination ports or port ranges of the rule, e.g. `9100` or `8000-8080`. Ports are between 1 and 65535, only `tcp` and `udp` rules can have ports.",
+			},
+			"src_cidr": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ipv4": {
+							Type: schema.TypeSet,
+							Set:  hashCidr,
+							Elem: &schema.Schema{
+								Type:             schema.TypeString,
+								ValidateFunc:     validateIpv4Cidr,
+								DiffSuppressFunc: suppressEquivalentCidr,
+							},
+							Optional:    true,
+							ForceNew:    true,
+							Description: "Provide allowed IPv4 addresses as string array for this rule. An address is equivalent to its `/32` network.",
+						},
+						"ipv6": {
+							Type: schema.TypeSet,
+							Set:  hashCidr,
+							Elem: &schema.Schema{
+								Type:             schema.TypeString,
+								ValidateFunc:     validateIpv6Cidr,
+								DiffSuppressFunc: suppressEquivalentCidr,
+							},
+							Optional:    true,
+							ForceNew:    true,
+							Description: "Provide allowed IPv6 addresses as string array for this rule. An address is equivalent to its `/128` network.",
+						},
+					},
+				},
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "accept",
+				ValidateFunc: validateFirewallAction,
+				Description:  "Action of the rule, currently there is just `accept`.",
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "active",
+				ValidateFunc: validateFirewallRuleStatus,
+				Description:  "Status of the rule. It can be `active`, or `inactive`.",
+			},
+		},
+	}
+}
+
+func resourceFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	firewallId := d.Get("firewall_id").(string)
+	direction := d.Get("direction").(string)
+	ruleRequest := buildFirewallRuleRequests([]interface{}{firewallRuleResourceMap(d)})[0]
+	key := firewallRuleRequestKey(ruleRequest)
+
+	err := updateFirewallRules(ctx, client, firewallId, func(rules *openapi.RulesRequest) error {
+		directionRules := firewallRulesOfDirection(rules, direction)
+		for _, rule := range *directionRules {
+			if firewallRuleRequestKey(rule) == key {
+				return fmt.Errorf("firewall %s already has this %s rule, import it with the id %s", firewallId, direction, firewallRuleId(firewallId, direction, key))
+			}
+		}
+		*directionRules = append(*directionRules, ruleRequest)
+		return nil
+	}, func(rules *openapi.RulesRequest) bool {
+		return hasFirewallRule(*firewallRulesOfDirection(rules, direction), firewallRuleHash(key))
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(firewallRuleId(firewallId, direction, key))
+	return resourceFirewallRuleRead(ctx, d, m)
+}
+
+func resourceFirewallRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	firewallId, direction, hash, err := parseFirewallRuleId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	res, httpResp, err := client.FirewallsApi.
+		RetrieveFirewall(ctx, firewallId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if isNotFound(httpResp) {
+		d.SetId("")
+		return diags
+	} else if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) != 1 {
+		return MultipleDataObjectsError(diags)
+	}
+
+	rules := res.Data[0].Rules.Inbound
+	if direction == outboundRuleKey {
+		rules = res.Data[0].Rules.Outbound
+	}
+	for _, rule := range rules {
+		if firewallRuleHash(firewallRuleResponseKey(rule)) == hash {
+			return AddFirewallRuleToData(firewallId, direction, rule, d, diags)
+		}
+	}
+
+	// the rule has been removed outside of terraform
+	d.SetId("")
+	return diags
+}
+
+func resourceFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	firewallId, direction, hash, err := parseFirewallRuleId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ruleRequest := buildFirewallRuleRequests([]interface{}{firewallRuleResourceMap(d)})[0]
+
+	err = updateFirewallRules(ctx, client, firewallId, func(rules *openapi.RulesRequest) error {
+		directionRules := firewallRulesOfDirection(rules, direction)
+		for i, rule := range *directionRules {
+			if firewallRuleHash(firewallRuleRequestKey(rule)) == hash {
+				(*directionRules)[i] = ruleRequest
+				return nil
+			}
+		}
+		return fmt.Errorf("%s rule %s of firewall %s has been removed", direction, hash, firewallId)
+	}, func(rules *openapi.RulesRequest) bool {
+		for _, rule := range *firewallRulesOfDirection(rules, direction) {
+			if firewallRuleRequestState(rule) == firewallRuleRequestState(ruleRequest) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceFirewallRuleRead(ctx, d, m)
+}
+
+func resourceFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	firewallId, direction, hash, err := parseFirewallRuleId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = updateFirewallRules(ctx, client, firewallId, func(rules *openapi.RulesRequest) error {
+		directionRules := firewallRulesOfDirection(rules, direction)
+		keptRules := make([]openapi.FirewallRuleRequest, 0, len(*directionRules))
+		for _, rule := range *directionRules {
+			if firewallRuleHash(firewallRuleRequestKey(rule)) != hash {
+				keptRules = append(keptRules, rule)
+			}
+		}
+		*directionRules = keptRules
+		return nil
+	}, func(rules *openapi.RulesRequest) bool {
+		return !hasFirewallRule(*firewallRulesOfDirection(rules, direction), hash)
+	})
+	if err != nil && !errors.Is(err, errFirewallNotFound) {
+		// a deleted firewall has no rules left
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceFirewallRuleImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	diags := resourceFirewallRuleRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, DiagnosticsToError(diags)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("firewall rule not found, the id has the format <firewall id>/<direction>/<hash of the rule>")
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func AddFirewallRuleToData(
+	firewallId string,
+	direction string,
+	rule openapi.FirewallRuleResponse,
+	d *schema.ResourceData,
+	diags diag.Diagnostics,
+) diag.Diagnostics {
+	if err := d.Set("firewall_id", firewallId); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("direction", direction); err != nil {
+		return diag.FromErr(err)
+	}
+	protocol := rule.Protocol
+	if protocol == "" {
+		protocol = "any"
+	}
+	if err := d.Set("protocol", protocol); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("dest_ports", rule.DestPorts); err != nil {
+		return diag.FromErr(err)
+	}
+	srcCidr := []interface{}{}
+	if len(rule.SrcCidr.GetIpv4()) > 0 || len(rule.SrcCidr.GetIpv6()) > 0 {
+		srcCidr = append(srcCidr, map[string]interface{}{
+			"ipv4": rule.SrcCidr.GetIpv4(),
+			"ipv6": rule.SrcCidr.GetIpv6(),
+		})
+	}
+	if err := d.Set("src_cidr", srcCidr); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("action", rule.Action); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("status", rule.Status); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+// firewallRuleResourceMap returns the arguments of a contabo_firewall_rule
+// in the shape of a rule of contabo_firewall.
+func firewallRuleResourceMap(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"protocol":   d.Get("protocol"),
+		"dest_ports": d.Get("dest_ports"),
+		"src_cidr":   d.Get("src_cidr"),
+		"action":     d.Get("action"),
+		"status":     d.Get("status"),
+	}
+}
+
+func firewallRulesOfDirection(rules *openapi.RulesRequest, direction string) *[]openapi.FirewallRuleRequest {
+	if direction == outboundRuleKey {
+		return &rules.Outbound
+	}
+	return &rules.Inbound
+}
+
+// hasFirewallRule tells whether one of the rules has the given hash.
+func hasFirewallRule(rules []openapi.FirewallRuleRequest, hash string) bool {
+	for _, rule := range rules {
+		if firewallRuleHash(firewallRuleRequestKey(rule)) == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// firewallRuleRequestState is the key of a rule with its action and status.
+func firewallRuleRequestState(rule openapi.FirewallRuleRequest) string {
+	return fmt.Sprintf("%s|%v|%v", firewallRuleRequestKey(rule), rule.Action, rule.Status)
+}
+
+func firewallRuleRequestKey(rule openapi.FirewallRuleRequest) string {
+	return firewallRuleKey(rule.Protocol, rule.DestPorts, rule.SrcCidr.GetIpv4(), rule.SrcCidr.GetIpv6())
+}
+
+func firewallRuleResponseKey(rule openapi.FirewallRuleResponse) string {
+	return firewallRuleKey(rule.Protocol, rule.DestPorts, rule.SrcCidr.GetIpv4(), rule.SrcCidr.GetIpv6())
+}
+
+func firewallRuleHash(key string) string {
+	return strconv.Itoa(schema.HashString(key))
+}
+
+func firewallRuleId(firewallId string, direction string, key string) string {
+	return fmt.Sprintf("%s/%s/%s", firewallId, direction, firewallRuleHash(key))
+}
+
+func parseFirewallRuleId(id string) (string, string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || (parts[1] != inboundRuleKey && parts[1] != outboundRuleKey) || parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid firewall rule id %q, expected <firewall id>/<direction>/<hash of the rule>", id)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
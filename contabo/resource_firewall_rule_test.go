@@ -0,0 +1,65 @@
+package contabo
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccContaboFirewallRuleBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboFirewallRuleConfig("active"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_firewall_rule.node_exporter", "status", "active"),
+					resource.TestCheckResourceAttr("contabo_firewall_rule.https", "direction", "outbound"),
+					resource.TestCheckResourceAttr("contabo_firewall_rule.https", "protocol", "tcp"),
+				),
+			},
+			{
+				Config: testCheckContaboFirewallRuleConfig("inactive"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_firewall_rule.node_exporter", "status", "inactive"),
+				),
+			},
+			{
+				ResourceName:      "contabo_firewall_rule.node_exporter",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckContaboFirewallRuleConfig(status string) string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_firewall" "rules_test" {
+			name   = "terraform-firewall-rules"
+			status = "active"
+		}
+
+		resource "contabo_firewall_rule" "node_exporter" {
+			firewall_id = contabo_firewall.rules_test.id
+			protocol    = "tcp"
+			dest_ports  = ["9100"]
+			status      = "` + status + `"
+			src_cidr {
+				ipv4 = ["10.0.0.0/8"]
+			}
+		}
+
+		resource "contabo_firewall_rule" "https" {
+			firewall_id = contabo_firewall.rules_test.id
+			direction   = "outbound"
+			# the case of the protocol is ignored, the rule is not replaced
+			protocol    = "TCP"
+			dest_ports  = ["443"]
+		}
+	`
+}
@@ -3,10 +3,12 @@ package contabo
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"contabo.com/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	uuid "github.com/satori/go.uuid"
 )
@@ -21,6 +23,7 @@ func TestAccContaboFirewallBasic(t *testing.T) {
 				Config: testCheckContaboFirewallConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testCheckContaboFirewallExists("contabo_firewall.new"),
+					resource.TestCheckResourceAttr("contabo_firewall.new", "rules.0.outbound.#", "1"),
 				),
 				ExpectNonEmptyPlan: true,
 			},
@@ -28,6 +31,42 @@ func TestAccContaboFirewallBasic(t *testing.T) {
 	})
 }
 
+func TestUnmanagedFirewallInstances(t *testing.T) {
+	managedInstanceIds := schema.NewSet(schema.HashInt, []interface{}{1, 2})
+
+	unmanaged := unmanagedFirewallInstances([]int{3, 2, 5, 1}, managedInstanceIds)
+	if !reflect.DeepEqual(unmanaged, []int{3, 5}) {
+		t.Errorf("unmanagedFirewallInstances() = %v, want [3 5]", unmanaged)
+	}
+	if unmanaged := unmanagedFirewallInstances([]int{1, 2}, managedInstanceIds); len(unmanaged) != 0 {
+		t.Errorf("unmanagedFirewallInstances() = %v, want none", unmanaged)
+	}
+}
+
+func TestFirewallInstanceDelta(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      []interface{}
+		new      []interface{}
+		toAdd    []interface{}
+		toRemove []interface{}
+	}{
+		{name: "unchanged", old: []interface{}{1, 2}, new: []interface{}{2, 1}, toAdd: []interface{}{}, toRemove: []interface{}{}},
+		{name: "one added", old: []interface{}{1, 2}, new: []interface{}{1, 2, 3}, toAdd: []interface{}{3}, toRemove: []interface{}{}},
+		{name: "one removed", old: []interface{}{1, 2, 3}, new: []interface{}{1, 3}, toAdd: []interface{}{}, toRemove: []interface{}{2}},
+		{name: "one replaced", old: []interface{}{1, 2}, new: []interface{}{1, 4}, toAdd: []interface{}{4}, toRemove: []interface{}{2}},
+		{name: "all replaced", old: []interface{}{5, 1}, new: []interface{}{7, 3, 9}, toAdd: []interface{}{3, 7, 9}, toRemove: []interface{}{1, 5}},
+		{name: "from none", old: []interface{}{}, new: []interface{}{20, 10}, toAdd: []interface{}{10, 20}, toRemove: []interface{}{}},
+		{name: "to none", old: []interface{}{20, 10}, new: []interface{}{}, toAdd: []interface{}{}, toRemove: []interface{}{10, 20}},
+	}
+	for _, c := range cases {
+		toAdd, toRemove := firewallInstanceDelta(schema.NewSet(schema.HashInt, c.old), schema.NewSet(schema.HashInt, c.new))
+		if !reflect.DeepEqual(toAdd, c.toAdd) || !reflect.DeepEqual(toRemove, c.toRemove) {
+			t.Errorf("%s: firewallInstanceDelta() = %v, %v, want %v, %v", c.name, toAdd, toRemove, c.toAdd, c.toRemove)
+		}
+	}
+}
+
 func testAccCheckFirewallDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*openapi.APIClient)
 
@@ -69,6 +108,15 @@ func testCheckContaboFirewallConfigBasic() string {
 						ipv4 = ["127.0.0.1", "6.6.6.6"]
 					}
 				}
+			outbound {
+				protocol = "tcp"
+				action = "accept"
+				status = "active"
+				dest_ports = ["443"]
+				src_cidr {
+						ipv4 = ["0.0.0.0/0"]
+					}
+				}
 			}
 		}
 	`
@@ -2,15 +2,24 @@ package contabo
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
 	"contabo.com/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	uuid "github.com/satori/go.uuid"
 )
 
+const (
+	onDestroyCancel  = "cancel"
+	onDestroyPrevent = "prevent"
+)
+
+const defaultPeriod = 1
+
 func resourceInstance() *schema.Resource {
 	return &schema.Resource{
 		Description:   "The Compute Management API allows you to manage compute resources (e.g. creation, deletion, starting, stopping) as well as managing snapshots and custom images. It also supports [cloud-init](https://cloud-init.io/) at least on our default images (for custom images you will need to provide cloud-init support packages). The API offers providing cloud-init scripts via the user_data field. Custom images must be provided in .qcow2 or .iso format.",
@@ -18,8 +27,9 @@ func resourceInstance() *schema.Resource {
 		ReadContext:   resourceInstanceRead,
 		UpdateContext: resourceInstanceUpdate,
 		DeleteContext: resourceInstanceDelete,
+		CustomizeDiff: resourceInstanceCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceInstanceImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -32,6 +42,7 @@ func resourceInstance() *schema.Resource {
 				Computed:    true,
 				Optional:    true,
 				Description: "The identifier of the existing compute instance. (override id)",
+				Deprecated:  "Use `terraform import contabo_instance.<name> <instance id>` to adopt an existing compute instance instead. Imported instances are fully read back, so the following plan does not reinstall them.",
 			},
 			"last_updated": {
 				Type:        schema.TypeString,
@@ -46,8 +57,7 @@ func resourceInstance() *schema.Resource {
 			"display_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Computed:    true,
-				Description: "The instance name chosen by the customer that will be shown in the customer panel.",
+				Description: "The instance name chosen by the customer that will be shown in the customer panel. Removing it clears the display name.",
 			},
 			"image_id": {
 				Type:        schema.TypeString,
@@ -59,13 +69,13 @@ func resourceInstance() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Instance Region where the compute instance should be located. Default region is the EU. Following regions are available: `EU`,`US-central`,`US-east`,`US-west`,`SIN`.",
+				Description: "CAUTION: On updating this value your server will be replaced! Instance Region where the compute instance should be located. Default region is the EU. Following regions are available: `EU`,`US-central`,`US-east`,`US-west`,`SIN`.",
 			},
 			"product_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Choose the VPS/VDS product you want to buy. See our products [here](https://api.contabo.com/#tag/Instances/operation/createInstance).",
+				Description: "CAUTION: On updating this value your server will be replaced! Choose the VPS/VDS product you want to buy. See our products [here](https://api.contabo.com/#tag/Instances/operation/createInstance).",
 			},
 			"ip_config": {
 				Type:     schema.TypeList,
@@ -156,9 +166,32 @@ func resourceInstance() *schema.Resource {
 				Description: "CAUTION: On updating this value your server will be reinstalled! Array of `secretIds` of public SSH keys for logging into as defaultUser with administrator/root privileges. Applies to Linux/BSD systems. Please refer to Secrets Management API.",
 			},
 			"root_password": {
-				Optional:    true,
-				Type:        schema.TypeInt,
-				Description: "CAUTION: On updating this value your server will be reinstalled! Root password of the compute instance.",
+				Optional:      true,
+				Type:          schema.TypeInt,
+				Sensitive:     true,
+				ConflictsWith: []string{"root_password_wo", "generate_root_password"},
+				Description:   "CAUTION: On updating this value your server will be reinstalled! Id of the `password` secret holding the root password of the compute instance.",
+			},
+			"root_password_wo": {
+				Optional:      true,
+				Type:          schema.TypeString,
+				Sensitive:     true,
+				ValidateFunc:  validateRootPassword,
+				ConflictsWith: []string{"root_password", "generate_root_password"},
+				Description:   "CAUTION: On updating this value your server will be reinstalled! Root password of the compute instance. It is stored in a temporary secret which is deleted once the instance is installed or its installation has failed. The password has to be 8 to 30 characters long and contain upper case letters, lower case letters and digits, allowed special characters are `" + rootPasswordSpecial + "`.",
+			},
+			"generate_root_password": {
+				Optional:      true,
+				Type:          schema.TypeBool,
+				Default:       false,
+				ConflictsWith: []string{"root_password", "root_password_wo"},
+				Description:   "CAUTION: On enabling this value your server will be reinstalled! Generate a random root password which is available in `generated_root_password`. The password is kept on reinstallations.",
+			},
+			"generated_root_password": {
+				Computed:    true,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Description: "The generated root password if `generate_root_password` is enabled.",
 			},
 			"created_date": {
 				Type:        schema.TypeString,
@@ -166,9 +199,27 @@ func resourceInstance() *schema.Resource {
 				Description: "The creation date of the compute instance.",
 			},
 			"cancel_date": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDate,
+				Description:  "The date (`YYYY-MM-DD`) on which the instance should be cancelled once it is destroyed. If not set, the instance is cancelled at the end of its contract period. Destroying the instance fails once the date has passed.",
+			},
+			"on_destroy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      onDestroyCancel,
+				ValidateFunc: validation.StringInSlice([]string{onDestroyCancel, onDestroyPrevent}, false),
+				Description:  "What happens to the instance on destroy. `cancel` cancels the instance at `cancel_date` or at the end of its contract period, the instance keeps running until then. `prevent` refuses to destroy the instance.",
+			},
+			"scheduled_cancel_date": {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The date on which the instance will be cancelled.",
+				Computed:    true,
+				Description: "The date on which the instance is going to be cancelled. Empty if no cancellation is pending.",
+			},
+			"cancellation_pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the instance has been cancelled and will vanish at `scheduled_cancel_date`. A pending cancellation can only be revoked in the [Customer Control Panel](https://new.contabo.com).",
 			},
 			"status": {
 				Type:        schema.TypeString,
@@ -200,7 +251,6 @@ func resourceInstance() *schema.Resource {
 						},
 					},
 				},
-
 			},
 			"error_message": {
 				Type:        schema.TypeString,
@@ -213,32 +263,59 @@ func resourceInstance() *schema.Resource {
 				Description: "InsInstance's category depending on Product Id. Following product types are available: `hdd`,`ssd`,`vds`,`nvme`.",
 			},
 			"user_data": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "CAUTION: On updating this value your server will be reinstalled! Cloud-Init Config in order to customize during start of compute instance.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateUserData,
+				Description:  "CAUTION: On updating this value your server will be reinstalled! Cloud-Init Config in order to customize during start of compute instance. `#cloud-config` YAML is validated while planning. Gzipped and base64 encoded payloads, e.g. rendered by the `contabo_cloudinit_config` data source, are accepted as well. They are sent compressed, at most 65536 bytes are accepted.",
 			},
 			"license": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Additional license in order to enhance your chosen product. It is mainly needed for software licenses on your product (not needed for windows). See our [api documentation](https://api.contabo.com/#tag/Instances/operation/createInstance) for all available licenses.",
+				Description: "CAUTION: On updating this value your server will be replaced! Additional license in order to enhance your chosen product. It is mainly needed for software licenses on your product (not needed for windows). See our [api documentation](https://api.contabo.com/#tag/Instances/operation/createInstance) for all available licenses. The API does not return the license, imported instances have none in their state. Setting it on an imported instance records it without replacing the server.",
 			},
 			"default_user": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Default user name created for login during (re-)installation with administrative privileges. Allowed values for Linux/BSD are admin (use sudo to apply administrative privileges like root) or root. Allowed values for Windows are admin (has administrative privileges like administrator) or administrator.",
+				Description: "Default user name created for login during (re-)installation with administrative privileges. Allowed values for Linux/BSD are admin (use sudo to apply administrative privileges like root) or root. Allowed values for Windows are admin (has administrative privileges like administrator) or administrator. Changing it alone does not reinstall the server, it is used on the next reinstallation.",
 			},
 			"period": {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Computed:    true,
-				Description: "Initial contract period in months. Available periods are: 1, 3, 6 and 12 months. The default setting is 1 month.",
+				Description: "Initial contract period in months. Available periods are: 1, 3, 6 and 12 months. The default setting is 1 month. The API does not allow to change the period of an existing instance, changes are rejected while planning. The period of imported instances is unknown, it can be set once to record the period of their contract.",
+			},
+			"contract_start_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date (`YYYY-MM-DD`) on which the contract of the compute instance started.",
+			},
+			"contract_end_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date (`YYYY-MM-DD`) on which the current contract term ends. This is the cancel date if the instance has been cancelled. Empty if `period` is unknown, e.g. for imported instances.",
+			},
+			"next_billing_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date (`YYYY-MM-DD`) on which the contract is renewed and billed for another `period`. Empty if the instance has been cancelled or `period` is unknown.",
+			},
+			"wait_for": waitForSchema(),
+			"ipv4_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The primary IPv4 address of the instance, same as `ip_config[0].v4[0].ip`.",
+			},
+			"ipv6_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The primary IPv6 address of the instance, same as `ip_config[0].v6[0].ip`.",
 			},
 			"additional_ips": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "All other additional IP addresses of the instance.",
+				Description: "All other additional IP addresses of the instance. Every entry holds either an additional IPv4 address or an additional IPv6 network.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"v4": {
@@ -264,6 +341,29 @@ func resourceInstance() *schema.Resource {
 								},
 							},
 						},
+						"v6": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ip": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "IP Address",
+									},
+									"netmask_cidr": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Netmask CIDR",
+									},
+									"gateway": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Gateway",
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -275,6 +375,10 @@ func resourceInstanceCreate(ctx context.Context, d *schema.ResourceData, m inter
 	var diags diag.Diagnostics
 	client := m.(*openapi.APIClient)
 
+	if diags := validateWaitFor(d); diags.HasError() {
+		return diags
+	}
+
 	extstingId := d.Get("existing_instance_id").(string)
 
 	if extstingId != "" {
@@ -283,72 +387,34 @@ func resourceInstanceCreate(ctx context.Context, d *schema.ResourceData, m inter
 		return resourceInstanceUpdate(ctx, d, m)
 	}
 
-	createInstanceRequest := openapi.NewCreateInstanceRequestWithDefaults()
-
-	displayName := d.Get("display_name").(string)
-	imageId := d.Get("image_id").(string)
-	region := d.Get("region").(string)
-	productId := d.Get("product_id").(string)
-	sshKeys := d.Get("ssh_keys")
-	rootPassword := d.Get("root_password")
-	userData := d.Get("user_data").(string)
-	license := d.Get("license").(string)
-	defaultUser := d.Get("default_user").(string)
-	period := d.Get("period").(int)
-
-	if displayName != "" {
-		createInstanceRequest.DisplayName = &displayName
-	}
-	if imageId != "" {
-		createInstanceRequest.ImageId = &imageId
-	}
-	if region != "" {
-		createInstanceRequest.Region = &region
-	}
-	if productId != "" {
-		createInstanceRequest.ProductId = &productId
-	}
-	if sshKeys != nil {
-		var sshKeys64 []int64
-		for _, key := range sshKeys.([]interface{}) {
-			sshKey := key.(int)
-			sshKeys64 = append(sshKeys64, int64(sshKey))
+	if d.Get("period").(int) == 0 {
+		if err := d.Set("period", defaultPeriod); err != nil {
+			return diag.FromErr(err)
 		}
-		createInstanceRequest.SshKeys = &sshKeys64
-	}
-	if rootPassword != nil {
-		rootPassword64 := int64(rootPassword.(int))
-		createInstanceRequest.RootPassword = &rootPassword64
 	}
-	if userData != "" {
-		createInstanceRequest.UserData = &userData
-	}
-	if license != "" {
-		createInstanceRequest.License = &license
+
+	createInstanceRequest := buildCreateInstanceRequest(d.Get)
+	rootPasswordSecretId, diags := createRootPasswordSecret(ctx, client, d)
+	if diags.HasError() {
+		return diags
 	}
-	if period != 0 {
-		createInstanceRequest.Period = int64(period)
+	if rootPasswordSecretId != nil {
+		createInstanceRequest.RootPassword = rootPasswordSecretId
 	}
 
-	if defaultUser != "" {
-		createInstanceRequest.DefaultUser = &defaultUser
+	instanceId, diags := createInstance(ctx, client, *createInstanceRequest)
+	if diags.HasError() {
+		return append(diags, deleteRootPasswordSecret(ctx, client, rootPasswordSecretId)...)
 	}
 
-	res, httpResp, err := client.InstancesApi.
-		CreateInstance(ctx).
-		XRequestId(uuid.NewV4().String()).
-		CreateInstanceRequest(*createInstanceRequest).
-		Execute()
+	d.SetId(strconv.FormatInt(instanceId, 10))
 
-	if err != nil {
-		return HandleResponseErrors(diags, httpResp)
-	} else if len(res.Data) != 1 {
-		return MultipleDataObjectsError(diags)
+	diags = resourceInstanceRead(ctx, d, m)
+	diags = append(diags, deleteRootPasswordSecret(ctx, client, rootPasswordSecretId)...)
+	if diags.HasError() {
+		return diags
 	}
-
-	d.SetId(strconv.Itoa(int(res.Data[0].InstanceId)))
-
-	return resourceInstanceRead(ctx, d, m)
+	return append(diags, waitForInstanceReady(ctx, d)...)
 }
 
 func resourceInstanceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -367,7 +433,11 @@ func resourceInstanceRead(ctx context.Context, d *schema.ResourceData, m interfa
 		return append(diags, diag...)
 	}
 
-	return AddInstanceToData(*instance, d, diags)
+	diags = AddInstanceToData(*instance, d, diags)
+	if cancelDate := instance.GetCancelDate(); cancelDate != "" && !diags.HasError() {
+		diags = append(diags, pendingCancellationWarning(instanceId, cancelDate))
+	}
+	return diags
 }
 
 func resourceInstanceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -377,62 +447,48 @@ func resourceInstanceUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if shouldUpdateInstanceValues(d) {
-		updateInstanceValues(d, client, ctx, instanceId, diags, m)
-	}
-	if shouldReinstall(d) {
-		reinstall(d, client, ctx, instanceId, diags, m)
-	}
-	return resourceInstanceRead(ctx, d, m)
-}
-
-func shouldUpdateInstanceValues(d *schema.ResourceData) bool {
-	updateChange := false
-	if d.HasChange("display_name") {
-		displayname := d.Get("display_name").(string)
-		if displayname != "" {
-			updateChange = true
+	plan := planInstanceUpdate(changedInstanceArguments(d.HasChange), d.GetChange)
+	if len(plan.patch) > 0 {
+		if diags = updateInstanceValues(d, client, ctx, instanceId, diags, m); diags.HasError() {
+			return diags
 		}
 	}
-	return updateChange
-}
-
-func shouldReinstall(d *schema.ResourceData) bool {
-	reinstallChange := false
-	if d.HasChange("ssh_keys") {
-		sshKeys := d.Get("ssh_keys")
-		if sshKeys != nil {
-			reinstallChange = true
+	if len(plan.upgrade) > 0 {
+		oldAddOns, newAddOns := d.GetChange("add_ons")
+		diags = append(diags, upgradeInstanceAddOns(ctx, client, instanceId, oldAddOns.([]interface{}), newAddOns.([]interface{}))...)
+		if diags.HasError() {
+			return diags
 		}
 	}
-
-	if d.HasChange("root_password") {
-		rootPassword := d.Get("root_password")
-		if rootPassword != nil {
-			reinstallChange = true
+	if len(plan.deferred) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Instance has not been reinstalled",
+			Detail:   fmt.Sprintf("The changes of %v are used on the next reinstallation of instance %d.", plan.deferred, instanceId),
+		})
+	}
+	reinstalled := false
+	var rootPasswordSecretId *int64
+	if len(plan.reinstall) > 0 {
+		if diags := validateWaitFor(d); diags.HasError() {
+			return diags
 		}
-	}
-
-	if d.HasChange("user_data") {
-		userData := d.Get("user_data").(string)
-		if userData != "" {
-			reinstallChange = true
+		rootPasswordSecretId, diags = createRootPasswordSecret(ctx, client, d)
+		if diags.HasError() {
+			return diags
 		}
-	}
-	if d.HasChange("image_id") {
-		imageId := d.Get("image_id").(string)
-		if imageId != "" {
-			reinstallChange = true
+		diags = reinstall(d, client, ctx, instanceId, rootPasswordSecretId, diags, m)
+		if diags.HasError() {
+			return append(diags, deleteRootPasswordSecret(ctx, client, rootPasswordSecretId)...)
 		}
+		reinstalled = true
 	}
-	if d.HasChange("default_user") {
-		defaultUser := d.Get("default_user").(string)
-		if defaultUser != "" {
-			reinstallChange = true
-		}
+	diags = append(diags, resourceInstanceRead(ctx, d, m)...)
+	diags = append(diags, deleteRootPasswordSecret(ctx, client, rootPasswordSecretId)...)
+	if reinstalled && !diags.HasError() {
+		diags = append(diags, waitForInstanceReady(ctx, d)...)
 	}
-
-	return reinstallChange
+	return diags
 }
 
 func updateInstanceValues(d *schema.ResourceData, client *openapi.APIClient, ctx context.Context, instanceId int64, diags diag.Diagnostics, m interface{}) diag.Diagnostics {
@@ -442,7 +498,7 @@ func updateInstanceValues(d *schema.ResourceData, client *openapi.APIClient, ctx
 	patchInstanceRequest.DisplayName = &displayName
 
 	res, httpResp, err := client.InstancesApi.
-		PatchInstance(context.Background(), instanceId).
+		PatchInstance(ctx, instanceId).
 		PatchInstanceRequest(patchInstanceRequest).
 		XRequestId(uuid.NewV4().String()).Execute()
 
@@ -455,19 +511,12 @@ func updateInstanceValues(d *schema.ResourceData, client *openapi.APIClient, ctx
 	return diags
 }
 
-func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Context, instanceId int64, diags diag.Diagnostics, m interface{}) diag.Diagnostics {
+func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Context, instanceId int64, rootPasswordSecretId *int64, diags diag.Diagnostics, m interface{}) diag.Diagnostics {
 	patchInstanceRequest := openapi.NewReinstallInstanceRequestWithDefaults()
 
 	if d.HasChange("ssh_keys") {
-		sshKeys := d.Get("ssh_keys")
-		if sshKeys != nil {
-			var sshKeys64 []int64
-			for _, key := range sshKeys.([]interface{}) {
-				sshKey := key.(int)
-				sshKeys64 = append(sshKeys64, int64(sshKey))
-			}
-			patchInstanceRequest.SshKeys = &sshKeys64
-		}
+		sshKeys64 := buildSshKeys(d.Get("ssh_keys"))
+		patchInstanceRequest.SshKeys = &sshKeys64
 	}
 
 	if d.HasChange("root_password") {
@@ -477,6 +526,9 @@ func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Co
 			patchInstanceRequest.RootPassword = &rootPassword64
 		}
 	}
+	if rootPasswordSecretId != nil {
+		patchInstanceRequest.RootPassword = rootPasswordSecretId
+	}
 
 	if d.HasChange("user_data") {
 		userData := d.Get("user_data").(string)
@@ -485,11 +537,10 @@ func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Co
 		}
 	}
 
-	if d.HasChange("default_user") {
-		defaultUser := d.Get("default_user").(string)
-		if defaultUser != "" {
-			patchInstanceRequest.DefaultUser = &defaultUser
-		}
+	// a changed default user may have been deferred to this reinstallation
+	defaultUser := d.Get("default_user").(string)
+	if defaultUser != "" {
+		patchInstanceRequest.DefaultUser = &defaultUser
 	}
 
 	imageId := d.Get("image_id").(string)
@@ -497,10 +548,123 @@ func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Co
 		patchInstanceRequest.ImageId = imageId
 	}
 
+	return append(diags, reinstallInstance(ctx, client, instanceId, *patchInstanceRequest)...)
+}
+
+func resourceInstanceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+	instanceId, err := strconv.ParseInt(d.Id(), 10, 64)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cancelDate := d.Get("cancel_date").(string)
+	scheduledCancelDate := d.Get("scheduled_cancel_date").(string)
+
+	cancel, diags := planInstanceDestroy(
+		instanceId,
+		d.Get("on_destroy").(string),
+		cancelDate,
+		d.Get("cancellation_pending").(bool),
+		scheduledCancelDate,
+		time.Now(),
+	)
+	if diags.HasError() {
+		return diags
+	}
+	if !cancel {
+		d.SetId("")
+		return append(diags, pendingCancellationWarning(instanceId, scheduledCancelDate))
+	}
+
+	if diags := cancelInstance(ctx, client, instanceId, cancelDate); diags.HasError() {
+		return diags
+	}
+
+	if cancelDate == "" {
+		cancelDate = "the end of its contract period"
+	}
+	d.SetId("")
+	return append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Instance has been cancelled",
+		Detail:   fmt.Sprintf("Instance %d has been removed from the state but keeps running until %s.", instanceId, cancelDate),
+	})
+}
+
+// planInstanceDestroy decides how an instance is destroyed. It returns
+// whether the instance has to be cancelled, false if a pending cancellation
+// already does what destroy is asked to do.
+func planInstanceDestroy(
+	instanceId int64,
+	onDestroy string,
+	cancelDate string,
+	cancellationPending bool,
+	scheduledCancelDate string,
+	now time.Time,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if onDestroy == onDestroyPrevent {
+		return false, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Instance is protected from being destroyed",
+			Detail:   fmt.Sprintf("Instance %d has on_destroy set to %q. Set on_destroy to %q in order to cancel it.", instanceId, onDestroyPrevent, onDestroyCancel),
+		})
+	}
+
+	// Cancelling an instance a second time fails, a pending cancellation
+	// with the requested date already does what destroy is asked to do.
+	if cancellationPending && (cancelDate == "" || cancelDate == scheduledCancelDate) {
+		return false, diags
+	}
+
+	if today := now.UTC().Format(dateLayout); cancelDate != "" && cancelDate < today {
+		return false, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Instance can not be cancelled in the past",
+			Detail:   fmt.Sprintf("The cancel_date %s of instance %d has passed. Set a date from %s on, or remove cancel_date to cancel the instance at the end of its contract period.", cancelDate, instanceId, today),
+		})
+	}
+	return true, diags
+}
+
+// createInstance orders a new compute instance and returns its id. The
+// instance is still being installed when createInstance returns, use
+// pollInstanceInstalled to wait for it.
+func createInstance(
+	ctx context.Context,
+	client *openapi.APIClient,
+	createInstanceRequest openapi.CreateInstanceRequest,
+) (int64, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	res, httpResp, err := client.InstancesApi.
+		CreateInstance(ctx).
+		XRequestId(uuid.NewV4().String()).
+		CreateInstanceRequest(createInstanceRequest).
+		Execute()
+
+	if err != nil {
+		return 0, HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) != 1 {
+		return 0, MultipleDataObjectsError(diags)
+	}
+	return res.Data[0].InstanceId, diags
+}
+
+func reinstallInstance(
+	ctx context.Context,
+	client *openapi.APIClient,
+	instanceId int64,
+	reinstallInstanceRequest openapi.ReinstallInstanceRequest,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
 	res, httpResp, err := client.InstancesApi.
 		ReinstallInstance(ctx, instanceId).
 		XRequestId(uuid.NewV4().String()).
-		ReinstallInstanceRequest(*patchInstanceRequest).
+		ReinstallInstanceRequest(reinstallInstanceRequest).
 		Execute()
 
 	if err != nil {
@@ -508,107 +672,246 @@ func reinstall(d *schema.ResourceData, client *openapi.APIClient, ctx context.Co
 	} else if len(res.Data) != 1 {
 		return MultipleDataObjectsError(diags)
 	}
-	d.SetId(strconv.Itoa(int(res.Data[0].InstanceId)))
 	return diags
 }
 
-func resourceInstanceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+// cancelInstance cancels an instance at cancelDate, or at the end of its
+// contract period if cancelDate is empty.
+func cancelInstance(
+	ctx context.Context,
+	client *openapi.APIClient,
+	instanceId int64,
+	cancelDate string,
+) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	client := m.(*openapi.APIClient)
-	instanceId, err := strconv.ParseInt(d.Id(), 10, 64)
-	if err != nil {
-		return diag.FromErr(err)
+	cancelInstanceRequest := openapi.NewCancelInstanceRequestWithDefaults()
+	if cancelDate != "" {
+		cancelInstanceRequest.CancelDate = &cancelDate
 	}
 
 	_, httpResp, err := client.InstancesApi.
 		CancelInstance(ctx, instanceId).
 		XRequestId(uuid.NewV4().String()).
+		CancelInstanceRequest(*cancelInstanceRequest).
 		Execute()
 
 	if err != nil {
 		return HandleResponseErrors(diags, httpResp)
 	}
-
-	d.SetId("")
 	return diags
 }
 
-func AddInstanceToData(
-	instance openapi.InstanceResponse,
-	d *schema.ResourceData,
-	diags diag.Diagnostics,
-) diag.Diagnostics {
-	id := strconv.Itoa(int(instance.InstanceId))
-	if err := d.Set("id", id); err != nil {
-		return diag.FromErr(err)
+// buildCreateInstanceRequest builds the request from the arguments of an
+// instance. get returns the value of an argument, e.g. ResourceData.Get.
+func buildCreateInstanceRequest(get func(string) interface{}) *openapi.CreateInstanceRequest {
+	createInstanceRequest := openapi.NewCreateInstanceRequestWithDefaults()
+
+	if displayName := get("display_name").(string); displayName != "" {
+		createInstanceRequest.DisplayName = &displayName
 	}
-	if err := d.Set("name", instance.Name); err != nil {
-		return diag.FromErr(err)
+	if imageId := get("image_id").(string); imageId != "" {
+		createInstanceRequest.ImageId = &imageId
 	}
-	if err := d.Set("display_name", instance.DisplayName); err != nil {
-		return diag.FromErr(err)
+	if region := get("region").(string); region != "" {
+		createInstanceRequest.Region = &region
 	}
-	if err := d.Set("image_id", instance.ImageId); err != nil {
-		return diag.FromErr(err)
+	if productId := get("product_id").(string); productId != "" {
+		createInstanceRequest.ProductId = &productId
 	}
-	if err := d.Set("product_id", instance.ProductId); err != nil {
-		return diag.FromErr(err)
+	if sshKeys := buildSshKeys(get("ssh_keys")); len(sshKeys) > 0 {
+		createInstanceRequest.SshKeys = &sshKeys
 	}
-	ipConfig := buildIpConfig(&instance.IpConfig)
-	if err := d.Set("ip_config", ipConfig); err != nil && len(ipConfig) > 0 {
-		return diag.FromErr(err)
+	if rootPassword := int64(get("root_password").(int)); rootPassword != 0 {
+		createInstanceRequest.RootPassword = &rootPassword
 	}
-	if err := d.Set("mac_address", instance.MacAddress); err != nil {
-		return diag.FromErr(err)
+	if userData := get("user_data").(string); userData != "" {
+		createInstanceRequest.UserData = &userData
 	}
-	if err := d.Set("ram_mb", instance.RamMb); err != nil {
-		return diag.FromErr(err)
+	if license := get("license").(string); license != "" {
+		createInstanceRequest.License = &license
 	}
-	if err := d.Set("cpu_cores", instance.CpuCores); err != nil {
-		return diag.FromErr(err)
+	if period := get("period").(int); period != 0 {
+		createInstanceRequest.Period = int64(period)
 	}
-	if err := d.Set("disk_mb", instance.DiskMb); err != nil {
-		return diag.FromErr(err)
+	if defaultUser := get("default_user").(string); defaultUser != "" {
+		createInstanceRequest.DefaultUser = &defaultUser
 	}
-	if err := d.Set("os_type", instance.OsType); err != nil {
-		return diag.FromErr(err)
+
+	return createInstanceRequest
+}
+
+// buildReinstallInstanceRequest builds a request which reinstalls an
+// instance with all of its installation arguments, see
+// buildCreateInstanceRequest.
+func buildReinstallInstanceRequest(get func(string) interface{}) *openapi.ReinstallInstanceRequest {
+	reinstallInstanceRequest := openapi.NewReinstallInstanceRequestWithDefaults()
+
+	reinstallInstanceRequest.ImageId = get("image_id").(string)
+	if sshKeys := buildSshKeys(get("ssh_keys")); len(sshKeys) > 0 {
+		reinstallInstanceRequest.SshKeys = &sshKeys
 	}
-	if err := d.Set("ssh_keys", instance.SshKeys); err != nil {
-		return diag.FromErr(err)
+	if rootPassword := int64(get("root_password").(int)); rootPassword != 0 {
+		reinstallInstanceRequest.RootPassword = &rootPassword
 	}
-	createdDate := instance.CreatedDate.Format(time.RFC850)
-	if err := d.Set("created_date", createdDate); err != nil {
-		return diag.FromErr(err)
+	if userData := get("user_data").(string); userData != "" {
+		reinstallInstanceRequest.UserData = &userData
 	}
-	if err := d.Set("cancel_date", instance.CancelDate); err != nil {
-		return diag.FromErr(err)
+	if defaultUser := get("default_user").(string); defaultUser != "" {
+		reinstallInstanceRequest.DefaultUser = &defaultUser
 	}
-	if err := d.Set("status", instance.Status); err != nil {
-		return diag.FromErr(err)
+
+	return reinstallInstanceRequest
+}
+
+func buildSshKeys(sshKeys interface{}) []int64 {
+	var sshKeys64 []int64
+	if sshKeys == nil {
+		return sshKeys64
 	}
-	if err := d.Set("v_host_id", instance.VHostId); err != nil {
-		return diag.FromErr(err)
+	for _, key := range sshKeys.([]interface{}) {
+		sshKeys64 = append(sshKeys64, int64(key.(int)))
 	}
-	addOns := buildAddons(instance.AddOns)
-	if err := d.Set("add_ons", addOns); err != nil && len(addOns) > 0 {
-		return diag.FromErr(err)
+	return sshKeys64
+}
+
+func pendingCancellationWarning(instanceId int64, cancelDate string) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Instance is scheduled for cancellation",
+		Detail:   fmt.Sprintf("Instance %d will be cancelled on %s. The cancellation can only be revoked in the Customer Control Panel.", instanceId, cancelDate),
 	}
-	if err := d.Set("error_message", instance.ErrorMessage); err != nil {
-		return diag.FromErr(err)
+}
+
+func resourceInstanceImport(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) ([]*schema.ResourceData, error) {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	instanceId, err := strconv.ParseInt(d.Id(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance id %q: %v", d.Id(), err)
+	}
+
+	instance, diags := pollInstanceInstalled(diags, client, ctx, instanceId)
+	if instance == nil {
+		return nil, DiagnosticsToError(diags)
+	}
+
+	diags = AddInstanceToData(*instance, d, diags)
+	if diags.HasError() {
+		return nil, DiagnosticsToError(diags)
+	}
+
+	// Keep configurations which still use existing_instance_id free of diffs,
+	// the attribute can be dropped from the configuration afterwards.
+	if err := d.Set("existing_instance_id", d.Id()); err != nil {
+		return nil, err
+	}
+	if err := d.Set("on_destroy", onDestroyCancel); err != nil {
+		return nil, err
+	}
+	if err := d.Set("generate_root_password", false); err != nil {
+		return nil, err
 	}
-	if err := d.Set("product_type", instance.ProductType); err != nil {
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func AddInstanceToData(
+	instance openapi.InstanceResponse,
+	d *schema.ResourceData,
+	diags diag.Diagnostics,
+) diag.Diagnostics {
+	for key, value := range flattenInstance(instance) {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	contractEndDate, nextBillingDate := buildContractDates(instance.CreatedDate, d.Get("period").(int), instance.GetCancelDate(), time.Now())
+	if err := d.Set("contract_end_date", contractEndDate); err != nil {
 		return diag.FromErr(err)
 	}
-	additionalIps := buildAdditionalIps(instance.AdditionalIps)
-	if err := d.Set("additional_ips", additionalIps); err != nil &&
-		len(additionalIps) > 0 {
+	if err := d.Set("next_billing_date", nextBillingDate); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
+// flattenInstance returns the attributes of an instance which are shared by
+// the instance resource and the instance data sources.
+func flattenInstance(instance openapi.InstanceResponse) map[string]interface{} {
+	sshKeys := make([]interface{}, 0, len(instance.SshKeys))
+	for _, sshKey := range instance.SshKeys {
+		sshKeys = append(sshKeys, int(sshKey))
+	}
+
+	return map[string]interface{}{
+		"id":                    strconv.Itoa(int(instance.InstanceId)),
+		"name":                  instance.Name,
+		"display_name":          instance.DisplayName,
+		"image_id":              instance.ImageId,
+		"product_id":            instance.ProductId,
+		"region":                instance.Region,
+		"default_user":          instance.DefaultUser,
+		"ipv4_address":          instance.IpConfig.V4.Ip,
+		"ipv6_address":          instance.IpConfig.V6.Ip,
+		"ip_config":             buildIpConfig(&instance.IpConfig),
+		"mac_address":           instance.MacAddress,
+		"ram_mb":                int(instance.RamMb),
+		"cpu_cores":             int(instance.CpuCores),
+		"disk_mb":               int(instance.DiskMb),
+		"os_type":               instance.OsType,
+		"ssh_keys":              sshKeys,
+		"created_date":          instance.CreatedDate.Format(time.RFC850),
+		"scheduled_cancel_date": instance.GetCancelDate(),
+		"cancellation_pending":  instance.GetCancelDate() != "",
+		"contract_start_date":   instance.CreatedDate.Format(dateLayout),
+		"status":                string(instance.Status),
+		"v_host_id":             int(instance.VHostId),
+		"add_ons":               buildAddons(instance.AddOns),
+		"error_message":         instance.GetErrorMessage(),
+		"product_type":          instance.ProductType,
+		"additional_ips":        buildAdditionalIps(instance.AdditionalIps),
+	}
+}
+
+// buildContractDates returns the end of the current contract term and the
+// date of the next renewal. Contracts are renewed every period months
+// counted from the creation of the instance until they are cancelled.
+func buildContractDates(contractStart time.Time, period int, cancelDate string, now time.Time) (string, string) {
+	if cancelDate != "" {
+		return cancelDate, ""
+	}
+	if period <= 0 {
+		return "", ""
+	}
+
+	termEnd := contractStart
+	for terms := 1; !termEnd.After(now); terms++ {
+		termEnd = addContractMonths(contractStart, terms*period)
+	}
+	return termEnd.Format(dateLayout), termEnd.Format(dateLayout)
+}
+
+// addContractMonths adds months to the start of a contract. Contracts
+// starting on a day the target month does not have end on its last day,
+// e.g. on 28 February instead of 3 March.
+func addContractMonths(contractStart time.Time, months int) time.Time {
+	year, month, day := contractStart.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, contractStart.Location())
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	hour, minute, second := contractStart.Clock()
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, hour, minute, second, contractStart.Nanosecond(), contractStart.Location())
+}
+
 func buildIpConfig(ipConfigResponse *openapi.IpConfig2) []interface{} {
 	if ipConfigResponse != nil {
 		ipConfig := make(map[string]interface{})
@@ -653,15 +956,27 @@ func buildAdditionalIps(
 	if additionalIpsResponse != nil {
 		additionalIps := []map[string]interface{}{}
 
-		for _, ipV4 := range additionalIpsResponse {
-			v4 := make(map[string]interface{})
-			ipConfig := make(map[string]interface{})
-			ipConfig["ip"] = ipV4.V4.Ip
-			ipConfig["netmask_cidr"] = ipV4.V4.NetmaskCidr
-			ipConfig["gateway"] = ipV4.V4.Gateway
+		for _, additionalIpResponse := range additionalIpsResponse {
+			additionalIp := make(map[string]interface{})
+			additionalIp["v4"] = []interface{}{}
+			additionalIp["v6"] = []interface{}{}
+
+			if ipV4, ok := additionalIpResponse.GetV4Ok(); ok && ipV4.Ip != "" {
+				ipConfig := make(map[string]interface{})
+				ipConfig["ip"] = ipV4.Ip
+				ipConfig["netmask_cidr"] = ipV4.NetmaskCidr
+				ipConfig["gateway"] = ipV4.Gateway
+				additionalIp["v4"] = []interface{}{ipConfig}
+			}
+			if ipV6, ok := additionalIpResponse.GetV6Ok(); ok && ipV6.Ip != "" {
+				ipConfig := make(map[string]interface{})
+				ipConfig["ip"] = ipV6.Ip
+				ipConfig["netmask_cidr"] = ipV6.NetmaskCidr
+				ipConfig["gateway"] = ipV6.Gateway
+				additionalIp["v6"] = []interface{}{ipConfig}
+			}
 
-			v4["v4"] = []interface{}{ipConfig}
-			additionalIps = append(additionalIps, v4)
+			additionalIps = append(additionalIps, additionalIp)
 		}
 
 		return additionalIps
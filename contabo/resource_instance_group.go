@@ -0,0 +1,575 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+// instanceGroupReinstallArguments are the template arguments which are
+// applied to existing members by reinstalling them.
+var instanceGroupReinstallArguments = []string{
+	"template.0.image_id",
+	"template.0.ssh_keys",
+	"template.0.root_password",
+	"template.0.user_data",
+	"template.0.default_user",
+}
+
+type instanceGroupMember struct {
+	index       int
+	id          int64
+	displayName string
+	ipv4Address string
+	ipv6Address string
+	status      string
+}
+
+func resourceInstanceGroup() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Manages a group of identical compute instances created from a template. Members are named `<name>-<index>`, so removing a member does not rename the others. Changing the installation arguments of the template reinstalls the members in batches of `max_unavailable`. Members which are removed from the group are cancelled at the end of their contract period.",
+		CreateContext: resourceInstanceGroupCreate,
+		ReadContext:   resourceInstanceGroupRead,
+		UpdateContext: resourceInstanceGroupUpdate,
+		DeleteContext: resourceInstanceGroupDelete,
+		CustomizeDiff: resourceInstanceGroupCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The identifier of the instance group.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the group, members are named `<name>-<index>`.",
+			},
+			"size": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The number of compute instances in the group. Scaling down cancels the members with the highest indexes.",
+			},
+			"max_unavailable": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of members which are reinstalled at the same time. Default is `1`.",
+			},
+			"max_surge": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of members which are created at the same time when the group is scaled up. Default is `1`.",
+			},
+			"template": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "The arguments every member is created with. Changes of `image_id`, `ssh_keys`, `root_password`, `user_data` and `default_user` reinstall all members, removing them does not. Changes of `product_id`, `region` and `license` replace all members, `period` can not be changed.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"product_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "CAUTION: On updating this value all members will be replaced! The VPS/VDS product of the members. See our products [here](https://api.contabo.com/#tag/Instances/operation/createInstance).",
+						},
+						"region": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "CAUTION: On updating this value all members will be replaced! The region of the members. Default region is the EU.",
+						},
+						"image_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "CAUTION: On updating this value all members will be reinstalled! The image the members are installed with. Ubuntu 20.04 is the default. Without it members are reinstalled with the image they are installed with.",
+						},
+						"ssh_keys": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+							Description: "CAUTION: On updating this value all members will be reinstalled! Array of `secretIds` of public SSH keys for logging into as defaultUser with administrator/root privileges.",
+						},
+						"root_password": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "CAUTION: On updating this value all members will be reinstalled! Id of the `password` secret holding the root password of the members.",
+						},
+						"user_data": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateUserData,
+							Description:  "CAUTION: On updating this value all members will be reinstalled! Cloud-Init Config in order to customize the members during start.",
+						},
+						"license": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "CAUTION: On updating this value all members will be replaced! Additional license of the members.",
+						},
+						"default_user": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"root", "admin", "administrator"}, false),
+							Description:  "CAUTION: On updating this value all members will be reinstalled! Default user name created for login during (re-)installation with administrative privileges.",
+						},
+						"period": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultPeriod,
+							ValidateFunc: validation.IntInSlice([]int{1, 3, 6, 12}),
+							Description:  "Initial contract period of the members in months. Available periods are: 1, 3, 6 and 12 months. The default setting is 1 month. The API does not allow to change the period of existing members, changes are rejected while planning.",
+						},
+					},
+				},
+			},
+			"instances": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The members of the group ordered by their index.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The index of the member within the group.",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The identifier of the compute instance.",
+						},
+						"display_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The display name of the compute instance, `<name>-<index>`.",
+						},
+						"ipv4_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The primary IPv4 address of the compute instance.",
+						},
+						"ipv6_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The primary IPv6 address of the compute instance.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Status of the compute instance.",
+						},
+					},
+				},
+			},
+			"instance_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The identifiers of the members ordered by their index.",
+			},
+		},
+	}
+}
+
+func resourceInstanceGroupCreate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	d.SetId(uuid.NewV4().String())
+	return resourceInstanceGroupUpdate(ctx, d, m)
+}
+
+func resourceInstanceGroupRead(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	var members []instanceGroupMember
+	for _, member := range expandInstanceGroupMembers(d.Get("instances").([]interface{})) {
+		res, httpResp, err := client.InstancesApi.
+			RetrieveInstance(ctx, member.id).
+			XRequestId(uuid.NewV4().String()).
+			Execute()
+
+		if err != nil {
+			// members which have vanished are recreated on the next apply
+			if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return HandleResponseErrors(diags, httpResp)
+		} else if len(res.Data) == 0 {
+			continue
+		} else if len(res.Data) > 1 {
+			return MultipleDataObjectsError(diags)
+		}
+		members = append(members, newInstanceGroupMember(member.index, res.Data[0]))
+	}
+
+	return setInstanceGroupMembers(d, members, diags)
+}
+
+func resourceInstanceGroupUpdate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	name := d.Get("name").(string)
+	template := d.Get("template.0").(map[string]interface{})
+
+	// the members are taken from the state, instances is unknown in the plan
+	// once the size changes
+	oldInstances, _ := d.GetChange("instances")
+	members, removed, indexes := planInstanceGroupScaling(expandInstanceGroupMembers(oldInstances.([]interface{})), d.Get("size").(int))
+
+	// scale down first, the cancelled members keep running until the end of
+	// their contract period
+	if len(removed) > 0 {
+		diags = append(diags, cancelInstanceGroupMembers(ctx, client, removed)...)
+		if diags := setInstanceGroupMembers(d, members, diags); diags.HasError() {
+			return diags
+		}
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if !d.IsNewResource() && instanceGroupNeedsReinstall(d) {
+		diags = append(diags, reinstallInstanceGroupMembers(ctx, client, d, template, members)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if len(indexes) > 0 {
+		var created []instanceGroupMember
+		var createDiags diag.Diagnostics
+		var mutex sync.Mutex
+
+		createDiags = runInBatches(indexes, d.Get("max_surge").(int), func(index int) diag.Diagnostics {
+			member, diags := createInstanceGroupMember(ctx, client, name, template, index)
+			if member != nil {
+				mutex.Lock()
+				created = append(created, *member)
+				mutex.Unlock()
+			}
+			return diags
+		})
+
+		// created members are stored even if others failed, so they are not
+		// ordered a second time on the next apply
+		members = append(members, created...)
+		diags = append(diags, createDiags...)
+		if diags := setInstanceGroupMembers(d, members, diags); diags.HasError() {
+			return diags
+		}
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, resourceInstanceGroupRead(ctx, d, m)...)
+}
+
+func resourceInstanceGroupDelete(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	client := m.(*openapi.APIClient)
+
+	members := expandInstanceGroupMembers(d.Get("instances").([]interface{}))
+	diags := cancelInstanceGroupMembers(ctx, client, members)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+	if len(members) == 0 {
+		return diags
+	}
+	return append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Instances have been cancelled",
+		Detail:   fmt.Sprintf("The %d members of instance group %s have been removed from the state but keep running until the end of their contract period.", len(members), d.Get("name").(string)),
+	})
+}
+
+// resourceInstanceGroupCustomizeDiff rejects period changes, which the API
+// can not apply to existing members, and plans an update if members have
+// vanished outside of terraform, as size itself is unchanged in that case.
+func resourceInstanceGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChange("template.0.period") {
+		oldPeriod, newPeriod := d.GetChange("template.0.period")
+		if err := checkInstancePeriodChange(oldPeriod.(int), newPeriod.(int)); err != nil {
+			return fmt.Errorf("template: %s", err)
+		}
+	}
+	if len(d.Get("instances").([]interface{})) != d.Get("size").(int) {
+		if err := d.SetNewComputed("instances"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("instance_ids")
+	}
+	return nil
+}
+
+func createInstanceGroupMember(
+	ctx context.Context,
+	client *openapi.APIClient,
+	name string,
+	template map[string]interface{},
+	index int,
+) (*instanceGroupMember, diag.Diagnostics) {
+	displayName := instanceGroupMemberName(name, index)
+	createInstanceRequest := buildCreateInstanceRequest(func(key string) interface{} {
+		if key == "display_name" {
+			return displayName
+		}
+		return template[key]
+	})
+
+	instanceId, diags := createInstance(ctx, client, *createInstanceRequest)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	member := &instanceGroupMember{index: index, id: instanceId, displayName: displayName}
+	instance, diags := pollInstanceInstalled(diags, client, ctx, instanceId)
+	if instance != nil {
+		*member = newInstanceGroupMember(index, *instance)
+	}
+	return member, diags
+}
+
+func reinstallInstanceGroupMembers(
+	ctx context.Context,
+	client *openapi.APIClient,
+	d *schema.ResourceData,
+	template map[string]interface{},
+	members []instanceGroupMember,
+) diag.Diagnostics {
+	reinstallInstanceRequest := buildReinstallInstanceRequest(func(key string) interface{} {
+		return template[key]
+	})
+
+	indexes := make([]int, len(members))
+	for position := range members {
+		indexes[position] = position
+	}
+
+	return runInBatches(indexes, d.Get("max_unavailable").(int), func(position int) diag.Diagnostics {
+		instanceId := members[position].id
+		memberRequest := *reinstallInstanceRequest
+		if memberRequest.ImageId == "" {
+			// without an image in the template the member keeps its image
+			instance, diags := pollInstanceInstalled(nil, client, ctx, instanceId)
+			if instance == nil {
+				return diags
+			}
+			memberRequest.ImageId = instance.ImageId
+		}
+		diags := reinstallInstance(ctx, client, instanceId, memberRequest)
+		if diags.HasError() {
+			return diags
+		}
+		_, diags = pollInstanceInstalled(diags, client, ctx, instanceId)
+		return diags
+	})
+}
+
+func cancelInstanceGroupMembers(
+	ctx context.Context,
+	client *openapi.APIClient,
+	members []instanceGroupMember,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, member := range members {
+		res, httpResp, err := client.InstancesApi.
+			RetrieveInstance(ctx, member.id).
+			XRequestId(uuid.NewV4().String()).
+			Execute()
+
+		if err != nil {
+			if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			diags = HandleResponseErrors(diags, httpResp)
+			continue
+		}
+		// cancelling an instance a second time fails
+		if len(res.Data) == 1 && res.Data[0].GetCancelDate() != "" {
+			continue
+		}
+		diags = append(diags, cancelInstance(ctx, client, member.id, "")...)
+	}
+
+	return diags
+}
+
+// runInBatches calls run for all items, at most batchSize at the same time.
+// Batches are started one after another and no further batch is started
+// once a batch has failed.
+func runInBatches(items []int, batchSize int, run func(int) diag.Diagnostics) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		for _, item := range items[start:end] {
+			wg.Add(1)
+			go func(item int) {
+				defer wg.Done()
+				itemDiags := run(item)
+				mutex.Lock()
+				diags = append(diags, itemDiags...)
+				mutex.Unlock()
+			}(item)
+		}
+		wg.Wait()
+
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// planInstanceGroupScaling splits the members of a group into the kept and
+// the removed ones and returns the indexes of the members which have to be
+// created to reach size. Members with the highest indexes are removed first.
+func planInstanceGroupScaling(members []instanceGroupMember, size int) ([]instanceGroupMember, []instanceGroupMember, []int) {
+	sorted := append([]instanceGroupMember(nil), members...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].index < sorted[j].index
+	})
+	if len(sorted) > size {
+		return sorted[:size], sorted[size:], nil
+	}
+	return sorted, nil, freeInstanceGroupIndexes(sorted, size-len(sorted))
+}
+
+// instanceGroupNeedsReinstall tells whether a changed installation argument
+// of the template has to be applied to the members. Like for contabo_instance
+// removing an argument does not change the installed members.
+func instanceGroupNeedsReinstall(d *schema.ResourceData) bool {
+	for _, key := range instanceGroupReinstallArguments {
+		if d.HasChange(key) && !isEmptyArgument(d.Get(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// freeInstanceGroupIndexes returns the lowest count indexes which are not
+// used by a member, so gaps left by vanished members are filled first.
+func freeInstanceGroupIndexes(members []instanceGroupMember, count int) []int {
+	used := make(map[int]bool)
+	for _, member := range members {
+		used[member.index] = true
+	}
+
+	var indexes []int
+	for index := 1; len(indexes) < count; index++ {
+		if !used[index] {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+func instanceGroupMemberName(name string, index int) string {
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
+func newInstanceGroupMember(index int, instance openapi.InstanceResponse) instanceGroupMember {
+	return instanceGroupMember{
+		index:       index,
+		id:          instance.InstanceId,
+		displayName: instance.DisplayName,
+		ipv4Address: instance.IpConfig.V4.Ip,
+		ipv6Address: instance.IpConfig.V6.Ip,
+		status:      string(instance.Status),
+	}
+}
+
+func expandInstanceGroupMembers(instances []interface{}) []instanceGroupMember {
+	var members []instanceGroupMember
+	for _, item := range instances {
+		memberMap := item.(map[string]interface{})
+		instanceId, err := strconv.ParseInt(memberMap["id"].(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		members = append(members, instanceGroupMember{
+			index:       memberMap["index"].(int),
+			id:          instanceId,
+			displayName: memberMap["display_name"].(string),
+			ipv4Address: memberMap["ipv4_address"].(string),
+			ipv6Address: memberMap["ipv6_address"].(string),
+			status:      memberMap["status"].(string),
+		})
+	}
+	return members
+}
+
+func setInstanceGroupMembers(d *schema.ResourceData, members []instanceGroupMember, diags diag.Diagnostics) diag.Diagnostics {
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].index < members[j].index
+	})
+
+	instances := make([]interface{}, 0, len(members))
+	instanceIds := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		instanceId := strconv.FormatInt(member.id, 10)
+		instances = append(instances, map[string]interface{}{
+			"index":        member.index,
+			"id":           instanceId,
+			"display_name": member.displayName,
+			"ipv4_address": member.ipv4Address,
+			"ipv6_address": member.ipv6Address,
+			"status":       member.status,
+		})
+		instanceIds = append(instanceIds, instanceId)
+	}
+
+	if err := d.Set("instances", instances); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("instance_ids", instanceIds); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
@@ -0,0 +1,188 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestAccContaboInstanceGroupBasic(t *testing.T) {
+	// instance ids of the members by their index, across all steps
+	memberIds := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckInstanceGroupDestroy(memberIds),
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboInstanceGroupConfig(2, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.#", "2"),
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.0.display_name", "group-test-1"),
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.1.display_name", "group-test-2"),
+					testCheckContaboInstanceGroupKeepsMembers("contabo_instance_group.group_test", memberIds),
+				),
+			},
+			{
+				Config: testCheckContaboInstanceGroupConfig(3, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.#", "3"),
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.2.index", "3"),
+					testCheckContaboInstanceGroupKeepsMembers("contabo_instance_group.group_test", memberIds),
+				),
+			},
+			{
+				Config: testCheckContaboInstanceGroupConfig(1, "#cloud-config\\npackages: [nginx]\\n"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.#", "1"),
+					resource.TestCheckResourceAttr("contabo_instance_group.group_test", "instances.0.display_name", "group-test-1"),
+					testCheckContaboInstanceGroupKeepsMembers("contabo_instance_group.group_test", memberIds),
+					testCheckContaboInstanceGroupMembersCancelled(memberIds, "2", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestPlanInstanceGroupScaling(t *testing.T) {
+	member := func(index int) instanceGroupMember {
+		return instanceGroupMember{index: index, id: int64(1000 + index)}
+	}
+
+	cases := []struct {
+		name    string
+		members []instanceGroupMember
+		size    int
+		kept    []instanceGroupMember
+		removed []instanceGroupMember
+		indexes []int
+	}{
+		{name: "new group", size: 2, indexes: []int{1, 2}},
+		{name: "unchanged", members: []instanceGroupMember{member(1), member(2)}, size: 2, kept: []instanceGroupMember{member(1), member(2)}},
+		{name: "scale up", members: []instanceGroupMember{member(1), member(2)}, size: 3, kept: []instanceGroupMember{member(1), member(2)}, indexes: []int{3}},
+		{name: "scale down", members: []instanceGroupMember{member(1), member(2), member(3)}, size: 1, kept: []instanceGroupMember{member(1)}, removed: []instanceGroupMember{member(2), member(3)}},
+		{name: "scale down unordered", members: []instanceGroupMember{member(3), member(1), member(2)}, size: 2, kept: []instanceGroupMember{member(1), member(2)}, removed: []instanceGroupMember{member(3)}},
+		{name: "scale to zero", members: []instanceGroupMember{member(1), member(2)}, size: 0, kept: []instanceGroupMember{}, removed: []instanceGroupMember{member(1), member(2)}},
+		{name: "vanished member", members: []instanceGroupMember{member(1), member(3)}, size: 3, kept: []instanceGroupMember{member(1), member(3)}, indexes: []int{2}},
+		{name: "vanished member and scale up", members: []instanceGroupMember{member(2)}, size: 3, kept: []instanceGroupMember{member(2)}, indexes: []int{1, 3}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kept, removed, indexes := planInstanceGroupScaling(c.members, c.size)
+			if len(kept) != len(c.kept) || (len(kept) > 0 && !reflect.DeepEqual(kept, c.kept)) {
+				t.Errorf("kept = %v, want %v", kept, c.kept)
+			}
+			if !reflect.DeepEqual(removed, c.removed) {
+				t.Errorf("removed = %v, want %v", removed, c.removed)
+			}
+			if !reflect.DeepEqual(indexes, c.indexes) {
+				t.Errorf("indexes = %v, want %v", indexes, c.indexes)
+			}
+		})
+	}
+}
+
+// testCheckContaboInstanceGroupKeepsMembers records the instance ids of the
+// members of a group and fails if a member recorded by a previous step has
+// been replaced by another instance.
+func testCheckContaboInstanceGroupKeepsMembers(n string, memberIds map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		count, err := strconv.Atoi(rs.Primary.Attributes["instances.#"])
+		if err != nil {
+			return err
+		}
+		for position := 0; position < count; position++ {
+			index := rs.Primary.Attributes[fmt.Sprintf("instances.%d.index", position)]
+			instanceId := rs.Primary.Attributes[fmt.Sprintf("instances.%d.id", position)]
+			if previousId, ok := memberIds[index]; ok && previousId != instanceId {
+				return fmt.Errorf("member %s of %s has been replaced, it is instance %s instead of %s", index, n, instanceId, previousId)
+			}
+			memberIds[index] = instanceId
+		}
+		return nil
+	}
+}
+
+// testCheckContaboInstanceGroupMembersCancelled checks that the members with
+// the given indexes have been cancelled.
+func testCheckContaboInstanceGroupMembersCancelled(memberIds map[string]string, indexes ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, index := range indexes {
+			instanceId, ok := memberIds[index]
+			if !ok {
+				return fmt.Errorf("member %s has never been created", index)
+			}
+			if err := testCheckInstanceCancelled(instanceId); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckInstanceGroupDestroy checks that every member a group ever had
+// has been cancelled.
+func testAccCheckInstanceGroupDestroy(memberIds map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, instanceId := range memberIds {
+			if err := testCheckInstanceCancelled(instanceId); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func testCheckInstanceCancelled(instanceId string) error {
+	client := testAccProvider.Meta().(*openapi.APIClient)
+
+	id, err := strconv.ParseInt(instanceId, 10, 64)
+	if err != nil {
+		return err
+	}
+	res, httpResp, err := client.InstancesApi.
+		RetrieveInstance(context.Background(), id).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+	if isNotFound(httpResp) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("instance %s could not be retrieved: %v", instanceId, err)
+	}
+	if len(res.Data) == 1 && res.Data[0].GetCancelDate() == "" {
+		return fmt.Errorf("instance %s has not been cancelled", instanceId)
+	}
+	return nil
+}
+
+func testCheckContaboInstanceGroupConfig(size int, userData string) string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance_group" "group_test" {
+			name            = "group-test"
+			size            = ` + strconv.Itoa(size) + `
+			max_surge       = 2
+			max_unavailable = 2
+
+			template {
+				image_id  = "66abf39a-ba8b-425e-a385-8eb347ceac10"
+				user_data = "` + userData + `"
+			}
+		}
+	`
+}
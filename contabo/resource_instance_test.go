@@ -1,9 +1,13 @@
 package contabo
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
+	"contabo.com/openapi"
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -24,6 +28,8 @@ func TestAccContaboInstanceBasic(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testCheckContaboInstanceExists("contabo_instance.update_reinstall_test"),
 					resource.TestCheckResourceAttr("contabo_instance.update_reinstall_test", "display_name", creationDisplayName),
+					resource.TestCheckResourceAttrPair("contabo_instance.update_reinstall_test", "ipv4_address", "contabo_instance.update_reinstall_test", "ip_config.0.v4.0.ip"),
+					resource.TestCheckResourceAttrPair("contabo_instance.update_reinstall_test", "ipv6_address", "contabo_instance.update_reinstall_test", "ip_config.0.v6.0.ip"),
 				),
 				PreventPostDestroyRefresh: true,
 			},
@@ -32,6 +38,7 @@ func TestAccContaboInstanceBasic(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testCheckContaboInstanceExists("contabo_instance.update_reinstall_test"),
 					resource.TestCheckResourceAttr("contabo_instance.update_reinstall_test", "image_id", "66abf39a-ba8b-425e-a385-8eb347ceac10"),
+					resource.TestCheckResourceAttr("contabo_instance.update_reinstall_test", "display_name", ""),
 				),
 				PreventPostDestroyRefresh: true,
 			},
@@ -52,10 +59,63 @@ func TestAccContaboInstanceBasic(t *testing.T) {
 				),
 				PreventPostDestroyRefresh: true,
 			},
+			{
+				Config: updateAndReinstallRemoveDisplayName(),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckContaboInstanceExists("contabo_instance.update_reinstall_test"),
+					resource.TestCheckResourceAttr("contabo_instance.update_reinstall_test", "display_name", ""),
+					testCheckContaboInstanceDisplayName("contabo_instance.update_reinstall_test", ""),
+				),
+				PreventPostDestroyRefresh: true,
+			},
 		},
 	})
 }
 
+func TestBuildContractDates(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Add(12 * time.Hour)
+	}
+
+	cases := []struct {
+		name            string
+		contractStart   string
+		period          int
+		cancelDate      string
+		now             string
+		contractEndDate string
+		nextBillingDate string
+	}{
+		{name: "first term", contractStart: "2023-01-10", period: 1, now: "2023-01-20", contractEndDate: "2023-02-10", nextBillingDate: "2023-02-10"},
+		{name: "renewal day", contractStart: "2023-01-10", period: 1, now: "2023-02-10", contractEndDate: "2023-03-10", nextBillingDate: "2023-03-10"},
+		{name: "later term", contractStart: "2023-01-10", period: 3, now: "2023-09-01", contractEndDate: "2023-10-10", nextBillingDate: "2023-10-10"},
+		{name: "month end", contractStart: "2023-01-31", period: 1, now: "2023-02-15", contractEndDate: "2023-02-28", nextBillingDate: "2023-02-28"},
+		{name: "month end in a long month", contractStart: "2023-01-31", period: 1, now: "2023-03-01", contractEndDate: "2023-03-31", nextBillingDate: "2023-03-31"},
+		{name: "month end in a short month", contractStart: "2023-03-31", period: 1, now: "2023-04-05", contractEndDate: "2023-04-30", nextBillingDate: "2023-04-30"},
+		{name: "month end in a leap year", contractStart: "2024-01-31", period: 1, now: "2024-02-10", contractEndDate: "2024-02-29", nextBillingDate: "2024-02-29"},
+		{name: "quarter into a leap year", contractStart: "2023-11-30", period: 3, now: "2024-01-01", contractEndDate: "2024-02-29", nextBillingDate: "2024-02-29"},
+		{name: "half year into a leap year", contractStart: "2023-08-31", period: 6, now: "2023-09-01", contractEndDate: "2024-02-29", nextBillingDate: "2024-02-29"},
+		{name: "leap day into a common year", contractStart: "2024-02-29", period: 12, now: "2024-06-01", contractEndDate: "2025-02-28", nextBillingDate: "2025-02-28"},
+		{name: "leap day into a leap year", contractStart: "2024-02-29", period: 12, now: "2027-06-01", contractEndDate: "2028-02-29", nextBillingDate: "2028-02-29"},
+		{name: "year end", contractStart: "2023-12-31", period: 1, now: "2024-01-15", contractEndDate: "2024-01-31", nextBillingDate: "2024-01-31"},
+		{name: "cancelled", contractStart: "2023-01-10", period: 1, cancelDate: "2023-05-10", now: "2023-03-01", contractEndDate: "2023-05-10", nextBillingDate: ""},
+		{name: "unknown period", contractStart: "2023-01-10", period: 0, now: "2023-03-01", contractEndDate: "", nextBillingDate: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			contractEndDate, nextBillingDate := buildContractDates(date(c.contractStart), c.period, c.cancelDate, date(c.now))
+			if contractEndDate != c.contractEndDate || nextBillingDate != c.nextBillingDate {
+				t.Errorf("buildContractDates() = %q, %q, want %q, %q", contractEndDate, nextBillingDate, c.contractEndDate, c.nextBillingDate)
+			}
+		})
+	}
+}
+
 func updateAndReinstallVPSCreation() string {
 	return `
 		provider "contabo" {}
@@ -98,6 +158,16 @@ func updateAndReinstallUpdateDisplayNameAndInstallArch() string {
 	`
 }
 
+func updateAndReinstallRemoveDisplayName() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "update_reinstall_test" {
+			image_id = "66abf39a-ba8b-425e-a385-8eb347ceac10"
+		}
+	`
+}
+
 func testCheckContaboInstanceExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -114,6 +184,72 @@ func testCheckContaboInstanceExists(n string) resource.TestCheckFunc {
 	}
 }
 
+// testCheckContaboInstanceDisplayName checks the display name of the instance
+// returned by the API, not the one in the state.
+func testCheckContaboInstanceDisplayName(n string, displayName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		client := testAccProvider.Meta().(*openapi.APIClient)
+		id, err := strconv.ParseInt(rs.Primary.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		res, _, err := client.InstancesApi.
+			RetrieveInstance(context.Background(), id).
+			XRequestId(uuid.New().String()).
+			Execute()
+		if err != nil {
+			return fmt.Errorf("instance %s could not be retrieved: %v", rs.Primary.ID, err)
+		} else if len(res.Data) != 1 {
+			return fmt.Errorf("instance %s could not be retrieved", rs.Primary.ID)
+		}
+		if res.Data[0].DisplayName != displayName {
+			return fmt.Errorf("instance %s has the display name %q, want %q", rs.Primary.ID, res.Data[0].DisplayName, displayName)
+		}
+		return nil
+	}
+}
+
 func testAccCheckInstanceDestroy(s *terraform.State) error {
 	return nil
 }
+
+func TestPlanInstanceDestroy(t *testing.T) {
+	now := time.Date(2023, 3, 1, 23, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	cases := []struct {
+		name                string
+		onDestroy           string
+		cancelDate          string
+		cancellationPending bool
+		scheduledCancelDate string
+		cancel              bool
+		valid               bool
+	}{
+		{name: "end of contract", onDestroy: onDestroyCancel, cancel: true, valid: true},
+		{name: "future date", onDestroy: onDestroyCancel, cancelDate: "2023-04-01", cancel: true, valid: true},
+		{name: "today", onDestroy: onDestroyCancel, cancelDate: "2023-03-01", cancel: true, valid: true},
+		{name: "past date", onDestroy: onDestroyCancel, cancelDate: "2023-02-28", valid: false},
+		{name: "prevent", onDestroy: onDestroyPrevent, valid: false},
+		{name: "prevent with pending cancellation", onDestroy: onDestroyPrevent, cancellationPending: true, scheduledCancelDate: "2023-04-01", valid: false},
+		{name: "pending cancellation", onDestroy: onDestroyCancel, cancellationPending: true, scheduledCancelDate: "2023-04-01", valid: true},
+		{name: "pending cancellation at the date", onDestroy: onDestroyCancel, cancelDate: "2023-04-01", cancellationPending: true, scheduledCancelDate: "2023-04-01", valid: true},
+		{name: "pending cancellation at another date", onDestroy: onDestroyCancel, cancelDate: "2023-05-01", cancellationPending: true, scheduledCancelDate: "2023-04-01", cancel: true, valid: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cancel, diags := planInstanceDestroy(100, c.onDestroy, c.cancelDate, c.cancellationPending, c.scheduledCancelDate, now)
+			if diags.HasError() == c.valid {
+				t.Fatalf("planInstanceDestroy() = %v, valid = %t", diags, c.valid)
+			}
+			if cancel != c.cancel {
+				t.Errorf("planInstanceDestroy() cancels = %t, want %t", cancel, c.cancel)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}\.?$`)
+
+func resourceReverseDns() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Manages the reverse DNS (PTR) record of an IPv4 or IPv6 address of one of your compute instances or VIPs. On destroy the PTR record is reset to the default of Contabo.",
+		CreateContext: resourceReverseDnsCreate,
+		ReadContext:   resourceReverseDnsRead,
+		UpdateContext: resourceReverseDnsUpdate,
+		DeleteContext: resourceReverseDnsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceReverseDnsImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP address of the PTR record.",
+			},
+			"ip_address": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.IsIPAddress,
+				DiffSuppressFunc: suppressEqualIpAddress,
+				Description:      "The IPv4 or IPv6 address of the PTR record. It has to belong to one of your compute instances or VIPs, IPv6 addresses have to be within the IPv6 network of a compute instance.",
+			},
+			"ptr": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validation.StringMatch(hostnameRegexp, "must be a fully qualified domain name"),
+				DiffSuppressFunc: suppressTrailingDot,
+				Description:      "The fully qualified domain name the IP address resolves to, e.g. `mail.example.com`.",
+			},
+			"instance_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The identifier of the compute instance the IP address belongs to. Empty for VIPs which are not assigned to a compute instance. It is looked up when the PTR record is created or imported, import the PTR record again after the IP address has moved to another compute instance.",
+			},
+		},
+	}
+}
+
+func resourceReverseDnsCreate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	ipAddress := canonicalIpAddress(d.Get("ip_address").(string))
+	ptr := strings.TrimSuffix(d.Get("ptr").(string), ".")
+
+	instanceId, diags := findIpAddressOwner(ctx, client, ipAddress)
+	if diags.HasError() {
+		return diags
+	}
+
+	createPtrRecordRequest := openapi.NewCreatePtrRecordRequestWithDefaults()
+	createPtrRecordRequest.IpAddress = ipAddress
+	createPtrRecordRequest.Ptr = ptr
+
+	_, httpResp, err := client.DNSApi.
+		CreatePtrRecord(ctx).
+		XRequestId(uuid.NewV4().String()).
+		CreatePtrRecordRequest(*createPtrRecordRequest).
+		Execute()
+
+	if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	}
+
+	d.SetId(ipAddress)
+	if err := d.Set("instance_id", instanceId); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceReverseDnsRead(ctx, d, m)
+}
+
+func resourceReverseDnsRead(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	res, httpResp, err := client.DNSApi.
+		RetrievePtrRecord(ctx, d.Id()).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if isNotFound(httpResp) || isForbidden(httpResp) {
+		return readMissingPtrRecord(ctx, client, d, httpResp)
+	} else if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	}
+
+	if len(res.Data) == 0 {
+		d.SetId("")
+		return diags
+	} else if len(res.Data) > 1 {
+		return MultipleDataObjectsError(diags)
+	}
+
+	return AddReverseDnsToData(res.Data[0], d, diags)
+}
+
+// readMissingPtrRecord handles a PTR record which can not be read. The
+// instances and VIPs of the account are only looked through then, as the IP
+// address might have left the account.
+func readMissingPtrRecord(
+	ctx context.Context,
+	client *openapi.APIClient,
+	d *schema.ResourceData,
+	httpResp *http.Response,
+) diag.Diagnostics {
+	_, owned, diags := lookupIpAddressOwner(ctx, client, d.Id())
+	if diags.HasError() {
+		return diags
+	}
+	if !owned {
+		// the PTR record of an IP address which has left the account is no
+		// longer managed by this resource
+		d.SetId("")
+		return append(diags, ipAddressNotOwnedDiagnostic(d.Id(), diag.Warning))
+	}
+	if isNotFound(httpResp) {
+		d.SetId("")
+		return diags
+	}
+	return HandleResponseErrors(diags, httpResp)
+}
+
+func resourceReverseDnsUpdate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	if d.HasChange("ptr") {
+		updatePtrRecordRequest := openapi.NewUpdatePtrRecordRequestWithDefaults()
+		updatePtrRecordRequest.Ptr = strings.TrimSuffix(d.Get("ptr").(string), ".")
+
+		_, httpResp, err := client.DNSApi.
+			UpdatePtrRecord(ctx, d.Id()).
+			XRequestId(uuid.NewV4().String()).
+			UpdatePtrRecordRequest(*updatePtrRecordRequest).
+			Execute()
+
+		if err != nil {
+			return HandleResponseErrors(diags, httpResp)
+		}
+		return resourceReverseDnsRead(ctx, d, m)
+	}
+
+	return diags
+}
+
+func resourceReverseDnsDelete(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	httpResp, err := client.DNSApi.
+		DeletePtrRecord(ctx, d.Id()).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil && (httpResp == nil || httpResp.StatusCode != http.StatusNotFound) {
+		return HandleResponseErrors(diags, httpResp)
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+func resourceReverseDnsImport(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) ([]*schema.ResourceData, error) {
+	client := m.(*openapi.APIClient)
+
+	if net.ParseIP(d.Id()) == nil {
+		return nil, fmt.Errorf("%q is not a valid IP address", d.Id())
+	}
+	ipAddress := canonicalIpAddress(d.Id())
+
+	instanceId, diags := findIpAddressOwner(ctx, client, ipAddress)
+	if diags.HasError() {
+		return nil, DiagnosticsToError(diags)
+	}
+
+	d.SetId(ipAddress)
+	if err := d.Set("ip_address", ipAddress); err != nil {
+		return nil, err
+	}
+	if err := d.Set("instance_id", instanceId); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func AddReverseDnsToData(
+	ptrRecord openapi.PtrRecordResponse,
+	d *schema.ResourceData,
+	diags diag.Diagnostics,
+) diag.Diagnostics {
+	if err := d.Set("ip_address", canonicalIpAddress(ptrRecord.IpAddress)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ptr", strings.TrimSuffix(ptrRecord.Ptr, ".")); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+// findIpAddressOwner returns the id of the compute instance the IP address
+// belongs to, or an empty id for a VIP which is not assigned to an instance.
+// IP addresses which do not belong to the account are an error.
+func findIpAddressOwner(ctx context.Context, client *openapi.APIClient, ipAddress string) (string, diag.Diagnostics) {
+	instanceId, owned, diags := lookupIpAddressOwner(ctx, client, ipAddress)
+	if diags.HasError() || owned {
+		return instanceId, diags
+	}
+	return "", append(diags, ipAddressNotOwnedDiagnostic(ipAddress, diag.Error))
+}
+
+// lookupIpAddressOwner is findIpAddressOwner reporting IP addresses which
+// do not belong to the account with owned set to false.
+func lookupIpAddressOwner(ctx context.Context, client *openapi.APIClient, ipAddress string) (string, bool, diag.Diagnostics) {
+	ip := net.ParseIP(ipAddress)
+
+	instances, diags := retrieveAllInstances(ctx, client, nil)
+	if diags.HasError() {
+		return "", false, diags
+	}
+	for _, instance := range instances {
+		if instanceOwnsIp(instance.IpConfig, instance.AdditionalIps, ip) {
+			return strconv.FormatInt(instance.InstanceId, 10), true, diags
+		}
+	}
+
+	vips, diags := retrieveAllVips(ctx, client)
+	if diags.HasError() {
+		return "", false, diags
+	}
+	for _, vip := range vips {
+		if ip.Equal(net.ParseIP(vipIp(vip))) {
+			return vip.ResourceId, true, diags
+		}
+	}
+
+	return "", false, diags
+}
+
+func ipAddressNotOwnedDiagnostic(ipAddress string, severity diag.Severity) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: severity,
+		Summary:  "IP address does not belong to your account",
+		Detail:   fmt.Sprintf("%s is neither an IP address of one of your compute instances nor one of your VIPs.", ipAddress),
+	}
+}
+
+func canonicalIpAddress(ipAddress string) string {
+	if ip := net.ParseIP(ipAddress); ip != nil {
+		return ip.String()
+	}
+	return ipAddress
+}
+
+func suppressEqualIpAddress(k, old, new string, d *schema.ResourceData) bool {
+	return canonicalIpAddress(old) == canonicalIpAddress(new)
+}
+
+func suppressTrailingDot(k, old, new string, d *schema.ResourceData) bool {
+	return strings.TrimSuffix(old, ".") == strings.TrimSuffix(new, ".")
+}
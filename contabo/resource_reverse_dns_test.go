@@ -0,0 +1,72 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"contabo.com/openapi"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var reverseDnsPtr = "terraform-" + (uuid.New()).String()[:8] + ".example.com"
+
+func TestAccContaboReverseDnsBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckReverseDnsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboReverseDnsConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("contabo_reverse_dns.ptr_test", "ptr", reverseDnsPtr),
+					resource.TestCheckResourceAttrPair("contabo_reverse_dns.ptr_test", "instance_id", "contabo_instance.ptr_test", "id"),
+				),
+			},
+			{
+				ResourceName:      "contabo_reverse_dns.ptr_test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckContaboReverseDnsConfigBasic() string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "ptr_test" {
+			display_name = "reverse dns test"
+		}
+
+		resource "contabo_reverse_dns" "ptr_test" {
+			ip_address = contabo_instance.ptr_test.ipv4_address
+			ptr        = "` + reverseDnsPtr + `"
+		}
+	`
+}
+
+func testAccCheckReverseDnsDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*openapi.APIClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "contabo_reverse_dns" {
+			continue
+		}
+
+		res, _, err := client.DNSApi.
+			RetrievePtrRecord(context.Background(), rs.Primary.ID).
+			XRequestId((uuid.New()).String()).
+			Execute()
+
+		if err == nil && len(res.Data) == 1 && res.Data[0].Ptr == reverseDnsPtr {
+			return fmt.Errorf("PTR record of %s has not been reset", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,324 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"contabo.com/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+)
+
+const vipResourceTypeInstances = "instances"
+
+func resourceVip() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Assigns a VIP (an additional or floating IP address you purchased) to a compute instance. Changing `instance_id` moves the VIP to another compute instance in the same region, e.g. to fail over a service. On destroy the VIP is unassigned but stays in your account.",
+		CreateContext: resourceVipCreate,
+		ReadContext:   resourceVipRead,
+		UpdateContext: resourceVipUpdate,
+		DeleteContext: resourceVipDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP address of the VIP.",
+			},
+			"ip": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsIPAddress,
+				Description:  "The IP address of the VIP. If not set, an unassigned VIP in the region of the compute instance is picked.",
+			},
+			"instance_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The identifier of the compute instance the VIP is assigned to. It has to be located in the region of the VIP.",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The region of the VIP.",
+			},
+			"data_center": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The data center of the VIP.",
+			},
+			"ip_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP version of the VIP, `v4` or `v6`.",
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the VIP, `additional` or `floating`.",
+			},
+		},
+	}
+}
+
+func resourceVipCreate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	instanceId, err := strconv.ParseInt(d.Get("instance_id").(string), 10, 64)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	region, diags := retrieveInstanceRegion(ctx, client, instanceId)
+	if diags.HasError() {
+		return diags
+	}
+
+	vip, diags := findVip(ctx, client, d.Get("ip").(string), region)
+	if diags.HasError() {
+		return diags
+	}
+	if diags := checkVipAssignable(*vip, instanceId, region); diags.HasError() {
+		return diags
+	}
+
+	ip := vipIp(*vip)
+	if vip.ResourceId != strconv.FormatInt(instanceId, 10) {
+		if diags := assignVip(ctx, client, ip, instanceId); diags.HasError() {
+			return diags
+		}
+	}
+
+	d.SetId(ip)
+	return resourceVipRead(ctx, d, m)
+}
+
+func resourceVipRead(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	res, httpResp, err := client.VIPApi.
+		RetrieveVip(ctx, d.Id()).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return diags
+		}
+		return HandleResponseErrors(diags, httpResp)
+	}
+
+	if len(res.Data) == 0 {
+		d.SetId("")
+		return diags
+	} else if len(res.Data) > 1 {
+		return MultipleDataObjectsError(diags)
+	}
+
+	return AddVipToData(res.Data[0], d, diags)
+}
+
+func resourceVipUpdate(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	if d.HasChange("instance_id") {
+		old, new := d.GetChange("instance_id")
+		newInstanceId, err := strconv.ParseInt(new.(string), 10, 64)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		region, diags := retrieveInstanceRegion(ctx, client, newInstanceId)
+		if diags.HasError() {
+			return diags
+		}
+		if region != d.Get("region").(string) {
+			return vipRegionError(diags, d.Id(), d.Get("region").(string), newInstanceId, region)
+		}
+
+		if old.(string) != "" {
+			oldInstanceId, err := strconv.ParseInt(old.(string), 10, 64)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if diags := unassignVip(ctx, client, d.Id(), oldInstanceId); diags.HasError() {
+				return diags
+			}
+		}
+		if diags := assignVip(ctx, client, d.Id(), newInstanceId); diags.HasError() {
+			return diags
+		}
+		return resourceVipRead(ctx, d, m)
+	}
+
+	return diags
+}
+
+func resourceVipDelete(
+	ctx context.Context,
+	d *schema.ResourceData,
+	m interface{},
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := m.(*openapi.APIClient)
+
+	if instanceId := d.Get("instance_id").(string); instanceId != "" {
+		instanceId, err := strconv.ParseInt(instanceId, 10, 64)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if diags := unassignVip(ctx, client, d.Id(), instanceId); diags.HasError() {
+			return diags
+		}
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+func AddVipToData(
+	vip openapi.VipResponse,
+	d *schema.ResourceData,
+	diags diag.Diagnostics,
+) diag.Diagnostics {
+	instanceId := ""
+	if vip.ResourceType == vipResourceTypeInstances {
+		instanceId = vip.ResourceId
+	}
+
+	if err := d.Set("ip", vipIp(vip)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("instance_id", instanceId); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("region", vip.Region); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("data_center", vip.DataCenter); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ip_version", vip.IpVersion); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("type", vip.Type); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+// findVip returns the VIP with the given IP address. Without an IP address
+// the first unassigned IPv4 VIP in the region is returned.
+func findVip(ctx context.Context, client *openapi.APIClient, ip string, region string) (*openapi.VipResponse, diag.Diagnostics) {
+	vips, diags := retrieveAllVips(ctx, client)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	for index, vip := range vips {
+		if ip != "" && canonicalIpAddress(vipIp(vip)) == canonicalIpAddress(ip) {
+			return &vips[index], diags
+		}
+		if ip == "" && vip.ResourceId == "" && vip.Region == region && vip.IpVersion == "v4" {
+			return &vips[index], diags
+		}
+	}
+
+	if ip != "" {
+		return nil, HandleMissingDataObjectsFilters(diags, "VIP not found", fmt.Sprintf("%s is not one of your VIPs.", ip))
+	}
+	return nil, HandleMissingDataObjectsFilters(diags, "No VIP available", fmt.Sprintf("There is no unassigned VIP in region %s, please purchase one in the Customer Control Panel.", region))
+}
+
+func checkVipAssignable(vip openapi.VipResponse, instanceId int64, region string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ip := vipIp(vip)
+
+	if vip.Region != region {
+		return vipRegionError(diags, ip, vip.Region, instanceId, region)
+	}
+	if vip.ResourceId != "" && vip.ResourceId != strconv.FormatInt(instanceId, 10) {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VIP is already assigned",
+			Detail:   fmt.Sprintf("VIP %s is assigned to %s %s. Import it with terraform import in order to move it to instance %d.", ip, vip.ResourceType, vip.ResourceId, instanceId),
+		})
+	}
+	return diags
+}
+
+func vipRegionError(diags diag.Diagnostics, ip string, vipRegion string, instanceId int64, instanceRegion string) diag.Diagnostics {
+	return append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "VIP and instance are located in different regions",
+		Detail:   fmt.Sprintf("VIP %s is located in region %s, instance %d in region %s. A VIP can only be assigned to instances in its region.", ip, vipRegion, instanceId, instanceRegion),
+	})
+}
+
+func retrieveInstanceRegion(ctx context.Context, client *openapi.APIClient, instanceId int64) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	res, httpResp, err := client.InstancesApi.
+		RetrieveInstance(ctx, instanceId).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil {
+		return "", HandleResponseErrors(diags, httpResp)
+	} else if len(res.Data) != 1 {
+		return "", MultipleDataObjectsError(diags)
+	}
+	return res.Data[0].Region, diags
+}
+
+func assignVip(ctx context.Context, client *openapi.APIClient, ip string, instanceId int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	_, httpResp, err := client.VIPApi.
+		AssignIp(ctx, vipResourceTypeInstances, instanceId, ip).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil {
+		return HandleResponseErrors(diags, httpResp)
+	}
+	return diags
+}
+
+func unassignVip(ctx context.Context, client *openapi.APIClient, ip string, instanceId int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	httpResp, err := client.VIPApi.
+		UnassignIp(ctx, vipResourceTypeInstances, instanceId, ip).
+		XRequestId(uuid.NewV4().String()).
+		Execute()
+
+	if err != nil && (httpResp == nil || httpResp.StatusCode != http.StatusNotFound) {
+		return HandleResponseErrors(diags, httpResp)
+	}
+	return diags
+}
@@ -0,0 +1,87 @@
+package contabo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"contabo.com/openapi"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccContaboVipBasic requires an unassigned VIP in the EU region.
+func TestAccContaboVipBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVipDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCheckContaboVipConfig("primary"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("contabo_vip.vip_test", "instance_id", "contabo_instance.primary", "id"),
+					resource.TestCheckResourceAttr("contabo_vip.vip_test", "region", "EU"),
+				),
+			},
+			{
+				Config: testCheckContaboVipConfig("secondary"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("contabo_vip.vip_test", "instance_id", "contabo_instance.secondary", "id"),
+					resource.TestCheckResourceAttrPair("data.contabo_vip.vip_test", "instance_id", "contabo_instance.secondary", "id"),
+				),
+			},
+			{
+				ResourceName:      "contabo_vip.vip_test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckContaboVipConfig(instance string) string {
+	return `
+		provider "contabo" {}
+
+		resource "contabo_instance" "primary" {
+			display_name = "vip primary"
+			region       = "EU"
+		}
+
+		resource "contabo_instance" "secondary" {
+			display_name = "vip secondary"
+			region       = "EU"
+		}
+
+		resource "contabo_vip" "vip_test" {
+			instance_id = contabo_instance.` + instance + `.id
+		}
+
+		data "contabo_vip" "vip_test" {
+			ip = contabo_vip.vip_test.ip
+		}
+	`
+}
+
+func testAccCheckVipDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*openapi.APIClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "contabo_vip" {
+			continue
+		}
+
+		res, _, err := client.VIPApi.
+			RetrieveVip(context.Background(), rs.Primary.ID).
+			XRequestId((uuid.New()).String()).
+			Execute()
+
+		if err == nil && len(res.Data) == 1 && res.Data[0].ResourceId != "" {
+			return fmt.Errorf("VIP %s is still assigned to %s", rs.Primary.ID, res.Data[0].ResourceId)
+		}
+	}
+
+	return nil
+}
@@ -2,7 +2,7 @@ package contabo
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"time"
 
 	"contabo.com/openapi"
@@ -12,46 +12,62 @@ import (
 
 const maxNumberOfRetries = 30
 const sleepInterval = 2000
+const dateLayout = "2006-01-02"
 
+// pollInstance waits until an instance is running, e.g. after an upgrade.
+// It fails if the instance can not be retrieved or is still not running
+// after maxNumberOfRetries polls.
 func pollInstance(diags diag.Diagnostics,
 	client *openapi.APIClient,
 	instanceId int64,
-) {
+) diag.Diagnostics {
 	numberOfRetries := 0
 	for numberOfRetries < maxNumberOfRetries {
-		if isInstanceRunning(diags, client, instanceId) {
-			return
+		running, diags := isInstanceRunning(diags, client, instanceId)
+		if diags.HasError() || running {
+			return diags
 		}
 		time.Sleep(sleepInterval * time.Millisecond)
 		numberOfRetries += 1
 	}
 
-	err := errors.New("Polling instance has failed.")
-	diag.FromErr(err)
-
+	err := fmt.Errorf("Polling instance %d has failed, it is not running.", instanceId)
+	return append(diags, diag.FromErr(err)...)
 }
 
 func isInstanceRunning(
 	diags diag.Diagnostics,
 	client *openapi.APIClient,
 	instanceId int64,
-) bool {
+) (bool, diag.Diagnostics) {
 	res, httpResp, err := client.InstancesApi.
 		RetrieveInstance(context.Background(), instanceId).
 		XRequestId(uuid.NewV4().String()).
 		Execute()
 
 	if err != nil {
-
-		HandleResponseErrors(diags, httpResp)
-		return false
+		return false, HandleResponseErrors(diags, httpResp)
 	}
 
 	if res.Data != nil && len(res.Data) == 1 {
 		if res.Data[0].Status == "running" {
-			return true
+			return true, diags
 		}
 	}
 
-	return false
+	return false, diags
+}
+
+func validateDate(v interface{}, k string) (warnings []string, errors []error) {
+	if _, err := time.Parse(dateLayout, v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a date in the format YYYY-MM-DD, got: %s", k, v))
+	}
+	return warnings, errors
+}
+
+func validateDuration(v interface{}, k string) (warnings []string, errors []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration like 10m or 1h, got: %s", k, v))
+	}
+	return warnings, errors
 }
@@ -0,0 +1,31 @@
+# Configure your Contabo API credentials
+provider "contabo" {
+  oauth2_client_id     = "[your client id]"
+  oauth2_client_secret = "[your client secret]"
+  oauth2_user          = "[your username]"
+  oauth2_pass          = "[your password]"
+}
+
+# Merge a cloud-config and a shell script into one multipart cloud-init config
+data "contabo_cloudinit_config" "web" {
+  gzip = true
+
+  part {
+    content = <<-EOT
+      #cloud-config
+      packages:
+        - nginx
+    EOT
+  }
+
+  part {
+    content_type = "text/x-shellscript"
+    filename     = "setup.sh"
+    content      = "#!/bin/sh\nsystemctl enable --now nginx\n"
+  }
+}
+
+resource "contabo_instance" "web" {
+  display_name = "web"
+  user_data    = data.contabo_cloudinit_config.web.rendered
+}
@@ -0,0 +1,9 @@
+# List all data centers in the EU offering object storages
+data "contabo_data_centers" "eu" {
+  region     = "EU"
+  capability = "Object-Storage"
+}
+
+output "s3_urls" {
+  value = data.contabo_data_centers.eu.data_centers[*].s3_url
+}
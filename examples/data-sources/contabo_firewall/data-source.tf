@@ -0,0 +1,9 @@
+# Look up a firewall by its name
+data "contabo_firewall" "web" {
+  name = "web"
+}
+
+# Look up a firewall by its id
+data "contabo_firewall" "by_id" {
+  id = "7e2b7f61-3a5c-4e6b-9e43-8d7e4b0f2a11"
+}
@@ -0,0 +1,41 @@
+# Expand named services into firewall rules
+data "contabo_firewall_rules" "web" {
+  cidr_group {
+    name = "office"
+    ipv4 = ["194.165.134.20"]
+  }
+
+  rule {
+    service     = "ssh"
+    cidr_groups = ["office", "private"]
+  }
+  rule {
+    service     = "http"
+    cidr_groups = ["any"]
+  }
+  rule {
+    service     = "https"
+    cidr_groups = ["any"]
+  }
+}
+
+resource "contabo_firewall" "web" {
+  name   = "web"
+  status = "active"
+
+  rules {
+    dynamic "inbound" {
+      for_each = data.contabo_firewall_rules.web.rules
+      content {
+        protocol   = inbound.value.protocol
+        dest_ports = inbound.value.dest_ports
+        action     = inbound.value.action
+        status     = inbound.value.status
+        src_cidr {
+          ipv4 = inbound.value.src_cidr[0].ipv4
+          ipv6 = inbound.value.src_cidr[0].ipv6
+        }
+      }
+    }
+  }
+}
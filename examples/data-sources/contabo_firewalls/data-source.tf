@@ -0,0 +1,14 @@
+# All active firewalls whose name starts with web
+data "contabo_firewalls" "web" {
+  name_regex = "^web"
+  status     = "active"
+}
+
+# The firewalls an instance is assigned to
+data "contabo_firewalls" "of_instance" {
+  instance_id = contabo_instance.web.id
+}
+
+output "firewall_ids" {
+  value = data.contabo_firewalls.of_instance.ids
+}
@@ -0,0 +1,13 @@
+# Look up the latest Ubuntu 22.04 standard image
+data "contabo_images" "ubuntu" {
+  name_regex     = "^ubuntu"
+  version_regex  = "^22\\.04"
+  standard_image = true
+  most_recent    = true
+}
+
+resource "contabo_instance" "web" {
+  display_name = "web"
+  region       = "EU"
+  image_id     = data.contabo_images.ubuntu.ids[0]
+}
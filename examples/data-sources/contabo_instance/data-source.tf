@@ -14,4 +14,9 @@ data "contabo_instance" "test_instance" {
 output "my_test_instance" {
   description = "my test instance"
   value = data.contabo_instance.test_instance
-}
\ No newline at end of file
+}
+
+# Search for a specific instance by its unique display name
+data "contabo_instance" "database_instance" {
+  display_name = "database"
+}
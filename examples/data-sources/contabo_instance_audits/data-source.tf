@@ -0,0 +1,11 @@
+# Find out who changed an instance in October
+data "contabo_instance_audits" "changes" {
+  instance_id = contabo_instance.database_instance.id
+  action      = "CHANGED"
+  start_date  = "2026-10-01"
+  end_date    = "2026-10-31"
+}
+
+output "changed_by" {
+  value = distinct(data.contabo_instance_audits.changes.audits[*].username)
+}
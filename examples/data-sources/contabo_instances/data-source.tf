@@ -0,0 +1,10 @@
+# List all running instances in the EU which are assigned to a tag
+data "contabo_instances" "web" {
+  region = "EU"
+  status = "running"
+  tag_id = contabo_tag.web.id
+}
+
+output "web_addresses" {
+  value = data.contabo_instances.web.instances[*].ipv4_address
+}
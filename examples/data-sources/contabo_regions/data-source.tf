@@ -0,0 +1,6 @@
+# List all regions
+data "contabo_regions" "all" {}
+
+output "regions" {
+  value = data.contabo_regions.all.slugs
+}
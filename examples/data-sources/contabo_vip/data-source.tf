@@ -0,0 +1,8 @@
+# Get a VIP by its IP address
+data "contabo_vip" "failover_ip" {
+  ip = "203.0.113.20"
+}
+
+output "failover_ip_instance" {
+  value = data.contabo_vip.failover_ip.instance_id
+}
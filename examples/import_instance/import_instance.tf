@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    contabo = {
+      source = "contabo/contabo"
+      version = ">= 0.1.42"
+    }
+  }
+}
+
+# Configure your Contabo API credentials in provider stanza
+provider "contabo" {
+  oauth2_client_id = "[your client id]"
+  oauth2_client_secret = "[your client secret]"
+  oauth2_user = "[your username]"
+  oauth2_pass = "[your password]"
+}
+
+# Describe the already purchased instance, then run
+#   terraform import contabo_instance.existing_instance 123456
+# Previously this was done with `existing_instance_id = "123456"`, which can be
+# removed from the configuration once the instance has been imported.
+resource "contabo_instance" "existing_instance" {
+  display_name = "existing instance"
+}
@@ -0,0 +1,5 @@
+# Import a firewall by its id
+terraform import contabo_firewall.web 7e2b7f61-3a5c-4e6b-9e43-8d7e4b0f2a11
+
+# Import a firewall by its name, it has to be unique
+terraform import contabo_firewall.web web
@@ -22,6 +22,15 @@ rules {
 				ipv6 = ["2001:0db8:85a3:0000:0000:8a2e:0370:7334"]
 			}
 		}
+	outbound {
+		protocol   = "tcp"
+		action     = "accept"
+		status     = "active"
+		dest_ports = ["443"]
+		src_cidr {
+				ipv4 = ["0.0.0.0/0"]
+			}
+		}
 	}
 }
 
@@ -0,0 +1,2 @@
+# Import an attachment by <firewall id>/<instance id>
+terraform import contabo_firewall_attachment.web 7e2b7f61-3a5c-4e6b-9e43-8d7e4b0f2a11/100
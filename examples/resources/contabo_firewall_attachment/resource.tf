@@ -0,0 +1,8 @@
+# Assign an instance to a shared firewall
+resource "contabo_firewall_attachment" "web" {
+  firewall_id = var.firewall_id
+  instance_id = contabo_instance.web.id
+
+  # book the charged firewall add-on if the instance does not have it yet
+  auto_enable_addon = true
+}
@@ -0,0 +1,2 @@
+# Import a firewall rule by <firewall id>/<direction>/<hash of the rule>
+terraform import contabo_firewall_rule.node_exporter 7e2b7f61-3a5c-4e6b-9e43-8d7e4b0f2a11/inbound/1816365292
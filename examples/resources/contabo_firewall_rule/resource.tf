@@ -0,0 +1,17 @@
+# Open the node exporter port of a shared firewall from a monitoring module
+resource "contabo_firewall_rule" "node_exporter" {
+  firewall_id = var.firewall_id
+  protocol    = "tcp"
+  dest_ports  = ["9100"]
+  src_cidr {
+    ipv4 = ["10.0.0.0/8"]
+  }
+}
+
+# Allow outgoing HTTPS only
+resource "contabo_firewall_rule" "https" {
+  firewall_id = var.firewall_id
+  direction   = "outbound"
+  protocol    = "tcp"
+  dest_ports  = ["443"]
+}
@@ -0,0 +1,2 @@
+# Import an already purchased compute instance by its instance id
+terraform import contabo_instance.database_instance 123456
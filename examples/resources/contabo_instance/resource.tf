@@ -18,3 +18,40 @@ resource "contabo_instance" "database_instance" {
 resource "contabo_instance" "database_instance" {
   image_id = contabo_image.custom_image_alpine.id
 }
+
+# Cancel the instance on a specific date once it is destroyed, until then it keeps running
+resource "contabo_instance" "temporary_instance" {
+  display_name = "temporary"
+  cancel_date  = "2026-12-31"
+}
+
+# Refuse to destroy an instance
+resource "contabo_instance" "protected_instance" {
+  display_name = "protected"
+  on_destroy   = "prevent"
+}
+
+# Wait until SSH is reachable and cloud-init has finished before continuing
+resource "contabo_instance" "ready_instance" {
+  display_name = "ready"
+  ssh_keys     = [contabo_secret.ssh_key.id]
+  user_data    = file("cloud-config.yaml")
+
+  wait_for {
+    tcp_port        = 22
+    cloud_init      = true
+    ssh_private_key = file("~/.ssh/id_ed25519")
+    timeout         = "15m"
+  }
+}
+
+# Generate a random root password, it is available as sensitive generated_root_password
+resource "contabo_instance" "generated_password_instance" {
+  display_name           = "generated password"
+  generate_root_password = true
+}
+
+output "root_password" {
+  value     = contabo_instance.generated_password_instance.generated_root_password
+  sensitive = true
+}
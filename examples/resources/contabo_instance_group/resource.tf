@@ -0,0 +1,20 @@
+# Create 40 identical instances named web-1 to web-40, 5 at a time.
+# Changing image_id reinstalls them, 4 at a time.
+resource "contabo_instance_group" "web" {
+  name            = "web"
+  size            = 40
+  max_surge       = 5
+  max_unavailable = 4
+
+  template {
+    product_id = "V45"
+    region     = "EU"
+    image_id   = "66abf39a-ba8b-425e-a385-8eb347ceac10"
+    ssh_keys   = [contabo_secret.ssh_key.id]
+    user_data  = file("cloud-config.yaml")
+  }
+}
+
+output "web_addresses" {
+  value = contabo_instance_group.web.instances[*].ipv4_address
+}
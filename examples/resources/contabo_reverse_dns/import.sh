@@ -0,0 +1,2 @@
+# Import the PTR record of an IP address
+terraform import contabo_reverse_dns.mail_v4 203.0.113.10
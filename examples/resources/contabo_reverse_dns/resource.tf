@@ -0,0 +1,11 @@
+# Set the PTR record of the primary IPv4 address of an instance
+resource "contabo_reverse_dns" "mail_v4" {
+  ip_address = contabo_instance.mail_relay.ipv4_address
+  ptr        = "mail.example.com"
+}
+
+# Set the PTR record of an address within the IPv6 network of an instance
+resource "contabo_reverse_dns" "mail_v6" {
+  ip_address = "2a02:c207:2012:3456::1"
+  ptr        = "mail.example.com"
+}
@@ -0,0 +1,2 @@
+# Import a VIP by its IP address
+terraform import contabo_vip.failover_ip 203.0.113.20
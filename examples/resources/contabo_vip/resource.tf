@@ -0,0 +1,10 @@
+# Assign a specific VIP to an instance, change instance_id to fail over
+resource "contabo_vip" "failover_ip" {
+  ip          = "203.0.113.20"
+  instance_id = contabo_instance.primary.id
+}
+
+# Assign any unassigned VIP in the region of the instance
+resource "contabo_vip" "additional_ip" {
+  instance_id = contabo_instance.primary.id
+}